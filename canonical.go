@@ -0,0 +1,227 @@
+package urlqm
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// CanonicalStep is a step of [Canonicalize], it transforms the params in place.
+// The params come from [Parser.ParseParams] with [Parser.KeepRaw] set,
+// so they keep their original encoding unless a step changes them.
+type CanonicalStep func(p *Params)
+
+// DefaultCanonicalSteps are the steps of [Canonicalize] if no steps are given.
+var DefaultCanonicalSteps = []CanonicalStep{NormalizeEncoding, RemoveEmpty, Dedupe, SortKeys, UnifySeparators}
+
+// Canonicalize returns the canonical form of the query string, produced by the given steps in their order,
+// or by [DefaultCanonicalSteps] if no steps are given. Equivalent query strings get the same canonical form,
+// so it can be used for deduplication and cache keys:
+//
+//	key := Canonicalize(u.RawQuery, NormalizeEncoding, Denylist(TrackingRules), SortKeys, UnifySeparators)
+//
+// Malformed params are kept as they are. To configure separators or decoding of '+', use [Parser.Canonicalize].
+func Canonicalize(query string, steps ...CanonicalStep) string {
+	return defaultParser.Canonicalize(query, steps...)
+}
+
+// Canonicalize returns the canonical form of the query string. See [Canonicalize].
+// The params are always parsed with [Parser.KeepRaw] set, and malformed params are kept even by a strict parser.
+func (p Parser) Canonicalize(query string, steps ...CanonicalStep) string {
+	p.KeepRaw, p.Strict = true, false
+	params, _ := p.ParseQuery(query)
+	if len(steps) == 0 {
+		steps = DefaultCanonicalSteps
+	}
+	for _, step := range steps {
+		step(&params)
+	}
+	return params.Encode()
+}
+
+// CanonicalizeURL replaces the raw query of the URL with its canonical form. See [Canonicalize].
+func CanonicalizeURL(u *url.URL, steps ...CanonicalStep) {
+	defaultParser.CanonicalizeURL(u, steps...)
+}
+
+// CanonicalizeURL replaces the raw query of the URL with its canonical form. See [Canonicalize].
+func (p Parser) CanonicalizeURL(u *url.URL, steps ...CanonicalStep) {
+	u.RawQuery = p.Canonicalize(u.RawQuery, steps...)
+	if u.RawQuery == "" {
+		u.ForceQuery = false
+	}
+}
+
+// SortKeys sorts the params by their decoded keys. Unlike [SortParams], the sort is stable,
+// so the values of a repeated key keep their order.
+func SortKeys(p *Params) {
+	sort.SliceStable(*p, func(i, j int) bool {
+		return (*p)[i].Key < (*p)[j].Key
+	})
+}
+
+// RemoveEmpty removes the params with empty values, like `a=`. Valueless params (flags) are kept.
+func RemoveEmpty(p *Params) {
+	p.deleteFunc(func(param Param) bool {
+		return param.Value == "" && !param.Flag
+	})
+}
+
+// Dedupe removes the params that repeat both the decoded key and the value of a previous param.
+// A param that had duplicates loses its original encoding, which would otherwise depend on
+// which of the duplicates comes first, and is encoded anew.
+func Dedupe(p *Params) {
+	type pair struct {
+		key, value string
+		flag       bool
+	}
+	first := make(map[pair]int, len(*p))
+	for i, param := range *p {
+		k := pair{param.Key, param.Value, param.Flag}
+		j, ok := first[k]
+		if !ok {
+			first[k] = i
+			continue
+		}
+		if raw := (*p)[j].raw; raw != nil {
+			// only the separator is kept: the empty raw key and value are used only for an empty key and value,
+			// which they encode anyway.
+			(*p)[j].raw = &rawParam{sep: raw.sep, lead: raw.lead, trail: raw.trail}
+		}
+	}
+	seen := make(map[pair]bool, len(*p))
+	p.deleteFunc(func(param Param) bool {
+		k := pair{param.Key, param.Value, param.Flag}
+		if seen[k] {
+			return true
+		}
+		seen[k] = true
+		return false
+	})
+}
+
+// NormalizeEncoding normalizes the percent-encoding of the keys and values, as RFC 3986 section 6.2.2 suggests:
+// the hex digits of escape sequences are uppercased, and the escaped unreserved characters
+// (letters, digits, '-', '.', '_' and '~') are decoded. The rest of the encoding, like '+' for a space, is kept.
+func NormalizeEncoding(p *Params) {
+	for i, param := range *p {
+		if param.raw == nil {
+			continue
+		}
+		raw := *param.raw
+		raw.key = normalizeEscapes(raw.key)
+		raw.value = normalizeEscapes(raw.value)
+		(*p)[i].raw = &raw
+	}
+}
+
+// UnifySeparators makes '&' the separator of all params.
+func UnifySeparators(p *Params) {
+	for i, param := range *p {
+		if param.raw == nil || param.raw.sep == "&" && !param.raw.lead && param.raw.trail == "" {
+			continue
+		}
+		raw := *param.raw
+		raw.sep, raw.lead, raw.trail = "&", false, ""
+		(*p)[i].raw = &raw
+	}
+}
+
+// Denylist returns a step that removes the params whose decoded keys are matched by any of the rules.
+func Denylist(rules ...Rule) CanonicalStep {
+	match := Rules(rules)
+	return func(p *Params) {
+		p.DeleteFunc(match.Match)
+	}
+}
+
+// CanonicalQuerySigV4 returns the canonical query string of AWS Signature Version 4:
+// keys and values are escaped with [RFC3986Escaper], sorted by the escaped key and then by the escaped value,
+// and joined with '&'. Every param has '=', even with an empty value or a [Param.Flag].
+func CanonicalQuerySigV4(params Params) string {
+	return encodeSortedRFC3986(params, nil)
+}
+
+// OAuthNormalizedParams returns the normalized parameter string of OAuth 1.0a (RFC 5849, section 3.4.1.3.2)
+// made of the params and the extra params. The params are usually those of the query string and the form body,
+// and the extra params are the oauth_* protocol params. The oauth_signature param is excluded.
+// The params are escaped, sorted and joined like [CanonicalQuerySigV4] does.
+func OAuthNormalizedParams(params Params, extra ...Param) string {
+	return encodeSortedRFC3986(append(params[:len(params):len(params)], extra...), func(key string) bool {
+		return key == "oauth_signature"
+	})
+}
+
+// encodeSortedRFC3986 escapes the params with [RFC3986Escaper], sorts them by key and value and joins them with '&'.
+// The params whose keys match skip are excluded.
+func encodeSortedRFC3986(params []Param, skip func(key string) bool) string {
+	pairs := make([][2]string, 0, len(params))
+	n := 0
+	for _, param := range params {
+		if skip != nil && skip(param.Key) {
+			continue
+		}
+		pair := [2]string{RFC3986Escaper.Escape(param.Key), ""}
+		if !param.Flag {
+			pair[1] = RFC3986Escaper.Escape(param.Value)
+		}
+		pairs = append(pairs, pair)
+		n += len(pair[0]) + len(pair[1]) + 2
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+
+	var buf strings.Builder
+	buf.Grow(n - 1)
+	for i, pair := range pairs {
+		if i > 0 {
+			buf.WriteByte('&')
+		}
+		buf.WriteString(pair[0])
+		buf.WriteByte('=')
+		buf.WriteString(pair[1])
+	}
+	return buf.String()
+}
+
+// normalizeEscapes uppercases the hex digits of escape sequences in s and decodes the escaped unreserved characters.
+// Invalid escape sequences are kept as they are.
+func normalizeEscapes(s string) string {
+	i := strings.IndexByte(s, '%')
+	if i < 0 {
+		return s
+	}
+	var buf strings.Builder
+	buf.Grow(len(s))
+	buf.WriteString(s[:i])
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c != '%' || i+2 >= len(s) || !ishex(s[i+1]) || !ishex(s[i+2]) {
+			buf.WriteByte(c)
+			continue
+		}
+		if d := unhex(s[i+1])<<4 | unhex(s[i+2]); isUnreserved(d) {
+			buf.WriteByte(d)
+		} else {
+			buf.WriteByte('%')
+			buf.WriteByte(upperHex(s[i+1]))
+			buf.WriteByte(upperHex(s[i+2]))
+		}
+		i += 2
+	}
+	return buf.String()
+}
+
+func upperHex(c byte) byte {
+	if 'a' <= c && c <= 'f' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
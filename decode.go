@@ -0,0 +1,207 @@
+package urlqm
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Unmarshal parses the query string and stores the params into the struct pointed to by v.
+// See [Params.Decode] for the rules. The query is parsed with [ParseParams],
+// so malformed params are decoded in their raw form, and the parse errors are returned
+// along with the decoding errors.
+func Unmarshal(rawQuery string, v any) error {
+	params, err := ParseParams(rawQuery)
+	return errors.Join(err, Params(params).Decode(v))
+}
+
+// Decode stores the params into the struct pointed to by v.
+//
+// A struct field receives the param with the key from its `query` tag, like `query:"page"`;
+// fields without the tag are ignored, and so are fields tagged `query:"-"`.
+// Fields of an embedded struct without the tag are decoded as if they were fields of the outer struct.
+// The supported field types are string, bool, integers, floats, [time.Duration], [time.Time],
+// types implementing [encoding.TextUnmarshaler], pointers to them and slices of them.
+// A [time.Time] is parsed with the layout from the `layout` tag, or with [time.RFC3339] by default:
+//
+//	type Filter struct {
+//		Query string    `query:"q"`
+//		Tags  []string  `query:"tag"`
+//		Page  *int      `query:"page"`
+//		Since time.Time `query:"since" layout:"2006-01-02"`
+//	}
+//
+// A slice receives all the values of the param, other fields receive the first one.
+// Fields of absent params stay untouched. An empty value sets the zero value of the field,
+// except for a bool field of a valueless param (a bare key), which is set to true.
+//
+// Decode doesn't stop on the first failed field, it decodes all it can
+// and reports the failed fields as [DecodeErrors].
+func (p Params) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrInvalidTarget
+	}
+	rv = rv.Elem()
+
+	byKey := make(map[string][]int, len(p))
+	for i, param := range p {
+		byKey[param.Key] = append(byKey[param.Key], i)
+	}
+
+	var errs DecodeErrors
+	for _, f := range cachedFields(rv.Type()) {
+		positions, ok := byKey[f.key]
+		if !ok {
+			continue
+		}
+		fv, err := fieldByIndexAlloc(rv, f.index)
+		if err != nil {
+			errs = append(errs, &DecodeError{Field: f.name, Key: f.key, Err: err})
+			continue
+		}
+		if fv.Kind() == reflect.Slice && !isTextUnmarshaler(fv.Type()) {
+			values := reflect.MakeSlice(fv.Type(), len(positions), len(positions))
+			failed := false
+			for i, pos := range positions {
+				if err := decodeValue(values.Index(i), p[pos], f.layout); err != nil {
+					errs = append(errs, &DecodeError{Field: f.name, Key: f.key, Value: p[pos].Value, Err: err})
+					failed = true
+				}
+			}
+			if !failed {
+				fv.Set(values)
+			}
+			continue
+		}
+		param := p[positions[0]]
+		if err := decodeValue(fv, param, f.layout); err != nil {
+			errs = append(errs, &DecodeError{Field: f.name, Key: f.key, Value: param.Value, Err: err})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+)
+
+func isTextUnmarshaler(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// fieldByIndexAlloc is like [reflect.Value.FieldByIndex], but it allocates nil embedded struct pointers on the way.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct %v", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// decodeValue stores the value of the param into v, allocating pointers if needed.
+func decodeValue(v reflect.Value, param Param, layout string) error {
+	if v.Kind() == reflect.Pointer && !isTextUnmarshaler(v.Type()) {
+		elem := reflect.New(v.Type().Elem())
+		if err := decodeValue(elem.Elem(), param, layout); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	s := param.Value
+	switch v.Type() {
+	case durationType:
+		if s == "" {
+			v.SetInt(0)
+			return nil
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case timeType:
+		if s == "" {
+			v.Set(reflect.Zero(timeType))
+			return nil
+		}
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if isTextUnmarshaler(v.Type()) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+		return nil
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if s == "" {
+			v.Set(reflect.Zero(v.Type()))
+			if v.Kind() == reflect.Bool && param.Flag {
+				v.SetBool(true)
+			}
+			return nil
+		}
+	default:
+		return fmt.Errorf("%w: %v", ErrUnsupportedType, v.Type())
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	}
+	return nil
+}
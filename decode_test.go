@@ -0,0 +1,192 @@
+package urlqm
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type decodePagination struct {
+	Page  int  `query:"page"`
+	Limit uint `query:"limit"`
+}
+
+type decodeTarget struct {
+	decodePagination
+	*DecodeSorting
+
+	Query    string        `query:"q"`
+	Tags     []string      `query:"tag"`
+	IDs      []int64       `query:"id"`
+	Ratio    float64       `query:"ratio"`
+	Debug    bool          `query:"debug"`
+	Verbose  bool          `query:"verbose"`
+	Timeout  time.Duration `query:"timeout"`
+	Since    time.Time     `query:"since" layout:"2006-01-02"`
+	Until    time.Time     `query:"until"`
+	Region   *string       `query:"region"`
+	Level    *int8         `query:"level"`
+	IP       net.IP        `query:"ip"`
+	Ignored  string        `query:"-"`
+	Untagged string
+	Limit    string `query:"limit"`
+}
+
+type DecodeSorting struct {
+	Sort string `query:"sort"`
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		init     decodeTarget
+		want     decodeTarget
+		wantErrs []string
+	}{
+		{
+			name:  "Empty",
+			query: "",
+			init:  decodeTarget{Query: "untouched"},
+			want:  decodeTarget{Query: "untouched"},
+		},
+		{
+			name: "All types",
+			query: "q=a+testing+query&tag=a&tag=b&id=1&id=2&ratio=0.5&debug&verbose=false&timeout=1m30s" +
+				"&since=2024-05-01&until=2024-05-01T10:00:00Z&region=eu&level=-3&ip=127.0.0.1" +
+				"&page=2&limit=ten&sort=name&Ignored=1&Untagged=1",
+			want: decodeTarget{
+				decodePagination: decodePagination{Page: 2},
+				DecodeSorting:    &DecodeSorting{Sort: "name"},
+				Query:            "a testing query",
+				Tags:             []string{"a", "b"},
+				IDs:              []int64{1, 2},
+				Ratio:            0.5,
+				Debug:            true,
+				Timeout:          90 * time.Second,
+				Since:            time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+				Until:            time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+				Region:           ptr("eu"),
+				Level:            ptr[int8](-3),
+				IP:               net.IPv4(127, 0, 0, 1),
+				Limit:            "ten",
+			},
+		},
+		{
+			name:  "First value for scalars",
+			query: "q=first&q=second&page=1&page=x",
+			want:  decodeTarget{Query: "first", decodePagination: decodePagination{Page: 1}},
+		},
+		{
+			name:  "Empty values set zero",
+			query: "q=&page=&debug=&region=&tag=",
+			init:  decodeTarget{Query: "init", decodePagination: decodePagination{Page: 5}, Debug: true},
+			want:  decodeTarget{Tags: []string{""}, Region: ptr("")},
+		},
+		{
+			name:  "Conversion errors are collected",
+			query: "page=x&id=1&id=b&level=300&debug=maybe&timeout=1y&since=2024/05/01&q=ok",
+			init:  decodeTarget{IDs: []int64{7}},
+			want:  decodeTarget{IDs: []int64{7}, Query: "ok"},
+			wantErrs: []string{
+				`urlqm: cannot decode parameter "page" value "x" into field decodePagination.Page: strconv.ParseInt: parsing "x": invalid syntax`,
+				`urlqm: cannot decode parameter "id" value "b" into field IDs: strconv.ParseInt: parsing "b": invalid syntax`,
+				`urlqm: cannot decode parameter "debug" value "maybe" into field Debug: strconv.ParseBool: parsing "maybe": invalid syntax`,
+				`urlqm: cannot decode parameter "timeout" value "1y" into field Timeout: time: unknown unit "y" in duration "1y"`,
+				`urlqm: cannot decode parameter "since" value "2024/05/01" into field Since: parsing time "2024/05/01" as "2006-01-02": cannot parse "/05/01" as "-"`,
+				`urlqm: cannot decode parameter "level" value "300" into field Level: strconv.ParseInt: parsing "300": value out of range`,
+			},
+		},
+		{
+			name:  "Malformed params are decoded raw",
+			query: "q=100%&page=3",
+			want:  decodeTarget{Query: "100%", decodePagination: decodePagination{Page: 3}},
+			wantErrs: []string{
+				`urlqm: parameter "q" at offset 0 has invalid value: invalid URL escape "%"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.init
+			err := Unmarshal(tt.query, &got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, tt.want)
+			}
+
+			var gotErrs []string
+			var decodeErrs DecodeErrors
+			if errors.As(err, &decodeErrs) {
+				for _, e := range decodeErrs {
+					gotErrs = append(gotErrs, e.Error())
+				}
+			}
+			var parseErrs ParseErrors
+			if errors.As(err, &parseErrs) {
+				for _, e := range parseErrs {
+					gotErrs = append(gotErrs, e.Error())
+				}
+			}
+			if (err != nil) != (len(gotErrs) > 0) {
+				t.Errorf("Unmarshal() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(gotErrs, tt.wantErrs) {
+				t.Errorf("Unmarshal() errors = %q, want %q", gotErrs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestParams_Decode(t *testing.T) {
+	type target struct {
+		Page int     `query:"page"`
+		Mode string  `query:"mode"`
+		Args []uint8 `query:"arg"`
+		Map  map[string]string
+	}
+
+	var got target
+	err := Params{{Key: "page", Value: "2"}, {Key: "arg", Value: "1"}, {Key: "arg", Value: "2"}}.Decode(&got)
+	if err != nil {
+		t.Fatalf("Params.Decode() error = %v", err)
+	}
+	if want := (target{Page: 2, Args: []uint8{1, 2}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Params.Decode() = %+v, want %+v", got, want)
+	}
+
+	var numErr *strconv.NumError
+	if err := (Params{{Key: "page", Value: "x"}}).Decode(&got); !errors.As(err, &numErr) {
+		t.Errorf("Params.Decode() error = %v, want *strconv.NumError", err)
+	}
+
+	type unsupported struct {
+		Map map[string]string `query:"map"`
+	}
+	if err := (Params{{Key: "map", Value: "x"}}).Decode(&unsupported{}); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Params.Decode() error = %v, want ErrUnsupportedType", err)
+	}
+	want := `urlqm: cannot decode parameter "map" value "x" into field Map: unsupported field type: map[string]string`
+	if err := (Params{{Key: "map", Value: "x"}}).Decode(&unsupported{}); err == nil || err.Error() != want {
+		t.Errorf("Params.Decode() error = %v, want %q", err, want)
+	}
+
+	type unexportedEmbedded struct {
+		*decodePagination
+	}
+	if err := (Params{{Key: "page", Value: "1"}}).Decode(&unexportedEmbedded{}); err == nil {
+		t.Errorf("Params.Decode() error = nil, want an error for nil embedded pointer to unexported struct")
+	}
+
+	for _, v := range []any{nil, got, &got.Page, (*target)(nil)} {
+		if err := (Params{}).Decode(v); !errors.Is(err, ErrInvalidTarget) {
+			t.Errorf("Params.Decode(%T) error = %v, want ErrInvalidTarget", v, err)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package urlqm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PatchAction is the kind of a [PatchOp].
+type PatchAction string
+
+const (
+	// PatchRemove removes a param.
+	PatchRemove PatchAction = "remove"
+	// PatchChange changes the value of a param.
+	PatchChange PatchAction = "change"
+	// PatchMove moves a param to another position.
+	PatchMove PatchAction = "move"
+	// PatchAdd adds a param.
+	PatchAdd PatchAction = "add"
+)
+
+// PatchOp is a single difference between two param lists, see [Diff].
+//
+// A param is identified by its key and the number of its occurrence among the params with the same key,
+// so repeated keys are compared one by one: the first `tag` of one list with the first `tag` of the other, and so on.
+type PatchOp struct {
+	Action PatchAction `json:"op"`
+	Key    string      `json:"key"`
+	// Index is the number of the occurrence of the key, 0 for the first one.
+	Index int `json:"index"`
+	// Pos is the position of the param in the old list for [PatchRemove],
+	// and in the new list for the other actions.
+	Pos int `json:"pos"`
+	// Value and Flag are the new value of the param, for [PatchChange] and [PatchAdd].
+	Value string `json:"value,omitempty"`
+	Flag  bool   `json:"flag,omitempty"`
+	// OldValue and OldFlag are the old value of the param, for [PatchRemove] and [PatchChange].
+	OldValue string `json:"old_value,omitempty"`
+	OldFlag  bool   `json:"old_flag,omitempty"`
+}
+
+// String returns the text form of the operation, like `change "sort"#0 "date" -> "name" at 1`.
+func (op PatchOp) String() string {
+	target := fmt.Sprintf("%q#%d", op.Key, op.Index)
+	switch op.Action {
+	case PatchRemove:
+		return fmt.Sprintf("%s %s %s at %d", op.Action, target, patchValue(op.OldValue, op.OldFlag), op.Pos)
+	case PatchChange:
+		return fmt.Sprintf("%s %s %s -> %s at %d", op.Action, target,
+			patchValue(op.OldValue, op.OldFlag), patchValue(op.Value, op.Flag), op.Pos)
+	case PatchAdd:
+		return fmt.Sprintf("%s %s %s at %d", op.Action, target, patchValue(op.Value, op.Flag), op.Pos)
+	}
+	return fmt.Sprintf("%s %s at %d", op.Action, target, op.Pos)
+}
+
+func patchValue(value string, flag bool) string {
+	if flag {
+		return "(flag)"
+	}
+	return fmt.Sprintf("%q", value)
+}
+
+// Patch is the list of differences between two param lists, made by [Diff].
+// The removals come first in the order of the old list, followed by the other operations
+// in the order of the new list. A nil Patch means the lists are equal.
+//
+// Patch has a stable text form, see [Patch.String], and its JSON form is a list of [PatchOp] objects.
+type Patch []PatchOp
+
+// Diff returns the patch that turns the params a into the params b.
+// Keys and values are compared in decoded form, so the params that differ only in encoding are equal.
+// Moves are reported for the fewest params that restore the order of b.
+func Diff(a, b Params) Patch {
+	type occurrence struct {
+		key string
+		n   int
+	}
+	count := make(map[string]int, len(a))
+	posA := make(map[occurrence]int, len(a))
+	for i, param := range a {
+		posA[occurrence{param.Key, count[param.Key]}] = i
+		count[param.Key]++
+	}
+
+	// pairs[j] is the position in a of the param that b[j] is paired with, or -1 for an added param.
+	pairs := make([]int, len(b))
+	indexB := make([]int, len(b))
+	paired := make([]bool, len(a))
+	var seq []int
+	count = make(map[string]int, len(b))
+	for j, param := range b {
+		o := occurrence{param.Key, count[param.Key]}
+		count[param.Key]++
+		indexB[j] = o.n
+		i, ok := posA[o]
+		if !ok {
+			pairs[j] = -1
+			continue
+		}
+		pairs[j] = i
+		paired[i] = true
+		seq = append(seq, i)
+	}
+
+	var patch Patch
+	count = make(map[string]int, len(a))
+	for i, param := range a {
+		n := count[param.Key]
+		count[param.Key]++
+		if !paired[i] {
+			patch = append(patch, PatchOp{Action: PatchRemove, Key: param.Key, Index: n, Pos: i,
+				OldValue: param.Value, OldFlag: param.Flag})
+		}
+	}
+
+	inOrder := longestIncreasing(seq)
+	k := 0
+	for j, param := range b {
+		i := pairs[j]
+		if i < 0 {
+			patch = append(patch, PatchOp{Action: PatchAdd, Key: param.Key, Index: indexB[j], Pos: j,
+				Value: param.Value, Flag: param.Flag})
+			continue
+		}
+		old := a[i]
+		if old.Flag != param.Flag || !param.Flag && old.Value != param.Value {
+			patch = append(patch, PatchOp{Action: PatchChange, Key: param.Key, Index: indexB[j], Pos: j,
+				Value: param.Value, Flag: param.Flag, OldValue: old.Value, OldFlag: old.Flag})
+		}
+		if !inOrder[k] {
+			patch = append(patch, PatchOp{Action: PatchMove, Key: param.Key, Index: indexB[j], Pos: j})
+		}
+		k++
+	}
+	return patch
+}
+
+// longestIncreasing reports which elements of seq make its longest increasing subsequence.
+func longestIncreasing(seq []int) []bool {
+	keep := make([]bool, len(seq))
+	if len(seq) == 0 {
+		return keep
+	}
+	// tails[k] is the index of the smallest tail of an increasing subsequence of length k+1.
+	tails := make([]int, 0, len(seq))
+	prev := make([]int, len(seq))
+	for i, v := range seq {
+		k := sort.Search(len(tails), func(k int) bool { return seq[tails[k]] >= v })
+		prev[i] = -1
+		if k > 0 {
+			prev[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+		keep[i] = true
+	}
+	return keep
+}
+
+// String returns the text form of the patch, one operation per line. See [PatchOp.String].
+func (patch Patch) String() string {
+	var buf strings.Builder
+	for _, op := range patch {
+		buf.WriteString(op.String())
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// Apply applies the patch to the params. Applied to the old params of [Diff], it makes the new ones.
+// Applied to other params, it does its best: the operations whose params are missing are skipped,
+// and the params are inserted at their positions or at the end if the list is shorter.
+// The patched params are a new slice, the slices that share the array of the old ones are left intact.
+func (patch Patch) Apply(p *Params) {
+	if len(patch) == 0 {
+		return
+	}
+	params := append(Params(nil), *p...)
+	removed := make(map[int]bool)
+	moved := make(map[int]int)
+	for k, op := range patch {
+		i := findOccurrence(params, op.Key, op.Index)
+		if i < 0 {
+			continue
+		}
+		switch op.Action {
+		case PatchRemove:
+			removed[i] = true
+		case PatchChange:
+			params[i].Value, params[i].Flag = op.Value, op.Flag
+		case PatchMove:
+			moved[k] = i
+		}
+	}
+	for _, i := range moved {
+		removed[i] = true
+	}
+
+	result := make(Params, 0, len(params)+len(patch))
+	for i, param := range params {
+		if !removed[i] {
+			result = append(result, param)
+		}
+	}
+
+	inserts := make([]int, 0, len(patch))
+	for k, op := range patch {
+		if _, ok := moved[k]; ok || op.Action == PatchAdd {
+			inserts = append(inserts, k)
+		}
+	}
+	sort.SliceStable(inserts, func(x, y int) bool { return patch[inserts[x]].Pos < patch[inserts[y]].Pos })
+	for _, k := range inserts {
+		op := patch[k]
+		param := Param{Key: op.Key, Value: op.Value, Flag: op.Flag}
+		if i, ok := moved[k]; ok {
+			param = params[i]
+		}
+		pos := op.Pos
+		if pos > len(result) {
+			pos = len(result)
+		}
+		result = append(result, Param{})
+		copy(result[pos+1:], result[pos:])
+		result[pos] = param
+	}
+	*p = result
+}
+
+// ApplyToQuery applies the patch to the params of the query string. The params that the patch doesn't touch
+// keep their original encoding and separators, see [Parser.KeepRaw].
+func (patch Patch) ApplyToQuery(query *string) {
+	if len(patch) == 0 {
+		return
+	}
+	params, _ := Parser{KeepRaw: true}.ParseQuery(*query)
+	patch.Apply(&params)
+	*query = params.Encode()
+}
+
+// findOccurrence returns the position of the n-th param with the key, or -1.
+func findOccurrence(params Params, key string, n int) int {
+	for i, param := range params {
+		if param.Key != key {
+			continue
+		}
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return -1
+}
@@ -0,0 +1,213 @@
+package urlqm
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "Empty",
+			a:    "",
+			b:    "",
+			want: "",
+		},
+		{
+			name: "Equal",
+			a:    "a=1&b=2&a=3",
+			b:    "a=1&b=2&a=3",
+			want: "",
+		},
+		{
+			name: "Equal in another encoding",
+			a:    "q=a+b&k=%7e",
+			b:    "q=a%20b&k=~",
+			want: "",
+		},
+		{
+			name: "Added",
+			a:    "a=1",
+			b:    "b=2&a=1&c",
+			want: "add \"b\"#0 \"2\" at 0\nadd \"c\"#0 (flag) at 2\n",
+		},
+		{
+			name: "Removed",
+			a:    "a=1&b=2&c",
+			b:    "b=2",
+			want: "remove \"a\"#0 \"1\" at 0\nremove \"c\"#0 (flag) at 2\n",
+		},
+		{
+			name: "Changed",
+			a:    "page=1&sort=date&debug",
+			b:    "page=2&sort=date&debug=1",
+			want: "change \"page\"#0 \"1\" -> \"2\" at 0\nchange \"debug\"#0 (flag) -> \"1\" at 2\n",
+		},
+		{
+			name: "Reordered",
+			a:    "a=1&b=2&c=3&d=4",
+			b:    "b=2&c=3&d=4&a=1",
+			want: "move \"a\"#0 at 3\n",
+		},
+		{
+			name: "Swapped",
+			a:    "a=1&b=2",
+			b:    "b=2&a=1",
+			want: "move \"b\"#0 at 0\n",
+		},
+		{
+			name: "Duplicate keys",
+			a:    "tag=go&tag=url&tag=query",
+			b:    "tag=go&tag=uri",
+			want: "remove \"tag\"#2 \"query\" at 2\nchange \"tag\"#1 \"url\" -> \"uri\" at 1\n",
+		},
+		{
+			name: "Duplicate keys added",
+			a:    "tag=go&q=1",
+			b:    "tag=go&tag=url&q=1&tag=x",
+			want: "add \"tag\"#1 \"url\" at 1\nadd \"tag\"#2 \"x\" at 3\n",
+		},
+		{
+			name: "Changed and moved",
+			a:    "a=1&b=2&c=3",
+			b:    "c=3&a=1&b=5&d=4",
+			want: "move \"c\"#0 at 0\nchange \"b\"#0 \"2\" -> \"5\" at 2\nadd \"d\"#0 \"4\" at 3\n",
+		},
+		{
+			name: "Everything",
+			a:    "x=0&a=1&b=2&a=2&c=3",
+			b:    "c=3&a=1&b=2&n=1&a=9",
+			want: "remove \"x\"#0 \"0\" at 0\nmove \"c\"#0 at 0\nadd \"n\"#0 \"1\" at 3\nchange \"a\"#1 \"2\" -> \"9\" at 4\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := ParseQuery(tt.a)
+			b, _ := ParseQuery(tt.b)
+			patch := Diff(a, b)
+			if got := patch.String(); got != tt.want {
+				t.Errorf("Diff() = %q, want %q", got, tt.want)
+			}
+
+			applied := append(Params(nil), a...)
+			patch.Apply(&applied)
+			if got, want := applied.Encode(), b.Encode(); got != want {
+				t.Errorf("Apply() = %q, want %q", got, want)
+			}
+			if len(Diff(applied, b)) != 0 {
+				t.Errorf("Diff() after Apply() = %q", Diff(applied, b).String())
+			}
+
+			data, err := json.Marshal(patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded Patch
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decoded, patch) {
+				t.Errorf("JSON round trip = %v, want %v", decoded, patch)
+			}
+		})
+	}
+}
+
+func TestDiff_Reorders(t *testing.T) {
+	queries := []string{
+		"a=1&b=2&c=3&d=4&e=5",
+		"e=5&d=4&c=3&b=2&a=1",
+		"b=2&a=1&d=4&c=3&e=5",
+		"c=3&a=1&e=5&b=2&d=4",
+		"a=1&a=2&b=1&a=3",
+		"a=3&b=1&a=2&a=1",
+	}
+	for _, from := range queries {
+		for _, to := range queries {
+			a, _ := ParseQuery(from)
+			b, _ := ParseQuery(to)
+			patch := Diff(a, b)
+			patch.Apply(&a)
+			if got := a.Encode(); got != b.Encode() {
+				t.Errorf("Diff(%q, %q).Apply() = %q", from, to, got)
+			}
+		}
+	}
+}
+
+func TestPatch_JSON(t *testing.T) {
+	a, _ := ParseQuery("page=1&debug")
+	b, _ := ParseQuery("page=2&q=go")
+	data, err := json.Marshal(Diff(a, b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"op":"remove","key":"debug","index":0,"pos":1,"old_flag":true},` +
+		`{"op":"change","key":"page","index":0,"pos":0,"value":"2","old_value":"1"},` +
+		`{"op":"add","key":"q","index":0,"pos":1,"value":"go"}]`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+}
+
+func TestPatch_ApplyKeepsSource(t *testing.T) {
+	a, _ := ParseQuery("q=go&page=1&sort=date")
+	b, _ := ParseQuery("q=rust&page=2")
+	patch := Diff(a, b)
+
+	src := a[:len(a):len(a)]
+	patch.Apply(&a)
+	if got := a.Encode(); got != "q=rust&page=2" {
+		t.Errorf("Apply() = %q, want %q", got, "q=rust&page=2")
+	}
+	if got := src.Encode(); got != "q=go&page=1&sort=date" {
+		t.Errorf("source after Apply() = %q, want it intact", got)
+	}
+}
+
+func TestPatch_ApplyToQuery(t *testing.T) {
+	a, _ := ParseQuery("q=a+b;page=1&sort=date")
+	b, _ := ParseQuery("q=a+b&sort=name&page=1&debug")
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "Old query",
+			query: "q=a+b;page=1&sort=date",
+			want:  "q=a+b&sort=name;page=1;debug",
+		},
+		{
+			name:  "Another query",
+			query: "page=1&x=%7e",
+			want:  "page=1&x=%7e&debug",
+		},
+		{
+			name:  "Empty query",
+			query: "",
+			want:  "debug",
+		},
+	}
+	patch := Diff(a, b)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			patch.ApplyToQuery(&query)
+			if query != tt.want {
+				t.Errorf("ApplyToQuery() = %q, want %q", query, tt.want)
+			}
+		})
+	}
+
+	query := "a=%7e"
+	Patch(nil).ApplyToQuery(&query)
+	if query != "a=%7e" {
+		t.Errorf("ApplyToQuery() with empty patch = %q", query)
+	}
+}
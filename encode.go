@@ -0,0 +1,159 @@
+package urlqm
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// QueryMarshaler is implemented by types that encode themselves into params.
+// MarshalQuery receives the key of the struct field and returns the params to put in place of the field,
+// so a value may be encoded as several params, or as params with other keys.
+type QueryMarshaler interface {
+	MarshalQuery(key string) ([]Param, error)
+}
+
+// Marshal returns the params of the struct v, or of the struct v points to, in the order of its fields.
+//
+// The fields are mapped to params by their `query` tags, like [Params.Decode] does,
+// and support the same types, see [Params.Decode].
+// Types implementing [QueryMarshaler] or [encoding.TextMarshaler] encode themselves,
+// a slice is encoded as a repeated key, a nil pointer is skipped,
+// and a [time.Time] is formatted with the layout from the `layout` tag, or with [time.RFC3339] by default.
+// The `omitempty` option skips a field with the zero value or an empty slice:
+//
+//	type Search struct {
+//		Query string   `query:"q"`
+//		Tags  []string `query:"tag"`
+//		Page  int      `query:"page,omitempty"`
+//	}
+//
+// Marshal(Search{Query: "urlqm", Tags: []string{"go", "url"}}) returns params for `q=urlqm&tag=go&tag=url`.
+func Marshal(v any) (Params, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, ErrInvalidValue
+	}
+
+	var params Params
+	for _, f := range cachedFields(rv.Type()) {
+		fv, ok := fieldByIndexNoAlloc(rv, f.index)
+		if !ok || f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		var err error
+		if params, err = appendField(params, fv, f); err != nil {
+			return nil, wrapError(err, "urlqm: cannot encode field %s", f.name)
+		}
+	}
+	return params, nil
+}
+
+var (
+	queryMarshalerType = reflect.TypeOf((*QueryMarshaler)(nil)).Elem()
+	textMarshalerType  = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// fieldByIndexNoAlloc is like [reflect.Value.FieldByIndex],
+// but it reports false instead of panicking on a nil embedded struct pointer.
+func fieldByIndexNoAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// asInterface returns v, or its address if v is addressable, as the interface type iface if it implements it.
+// A nil pointer is not returned.
+func asInterface(v reflect.Value, iface reflect.Type) (any, bool) {
+	if v.Type().Implements(iface) {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return nil, false
+		}
+		return v.Interface(), true
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(iface) {
+		return v.Addr().Interface(), true
+	}
+	return nil, false
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// appendField appends the params of the field value v.
+func appendField(params Params, v reflect.Value, f structField) (Params, error) {
+	if m, ok := asInterface(v, queryMarshalerType); ok {
+		fieldParams, err := m.(QueryMarshaler).MarshalQuery(f.key)
+		return append(params, fieldParams...), err
+	}
+	if _, ok := asInterface(v, textMarshalerType); !ok && v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			var err error
+			if params, err = appendField(params, v.Index(i), f); err != nil {
+				return params, err
+			}
+		}
+		return params, nil
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return params, nil
+		}
+		return appendField(params, v.Elem(), f)
+	}
+
+	value, err := formatValue(v, f.layout)
+	if err != nil {
+		return params, err
+	}
+	return append(params, Param{Key: f.key, Value: value}), nil
+}
+
+// formatValue returns the string form of v, the counterpart of decodeValue.
+func formatValue(v reflect.Value, layout string) (string, error) {
+	switch v.Type() {
+	case durationType:
+		return time.Duration(v.Int()).String(), nil
+	case timeType:
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return v.Interface().(time.Time).Format(layout), nil
+	}
+
+	if m, ok := asInterface(v, textMarshalerType); ok {
+		text, err := m.(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("%w: %v", ErrUnsupportedType, v.Type())
+}
@@ -0,0 +1,164 @@
+package urlqm
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// encodePagination is embedded unexported, its fields are promoted like the ones of an exported struct.
+type encodePagination struct {
+	Page  int  `query:"page"`
+	Limit uint `query:"limit"`
+}
+
+// dateRange encodes itself as two params, `<key>_from` and `<key>_to`.
+type dateRange struct {
+	From, To string
+}
+
+func (r dateRange) MarshalQuery(key string) ([]Param, error) {
+	if r.From == "" && r.To == "" {
+		return nil, errors.New("empty range")
+	}
+	return []Param{{Key: key + "_from", Value: r.From}, {Key: key + "_to", Value: r.To}}, nil
+}
+
+func TestMarshal(t *testing.T) {
+	type target struct {
+		encodePagination
+		*DecodeSorting
+
+		Query   string        `query:"q"`
+		Tags    []string      `query:"tag"`
+		IDs     []int64       `query:"id,omitempty"`
+		Ratio   float32       `query:"ratio"`
+		Debug   bool          `query:"debug,omitempty"`
+		Timeout time.Duration `query:"timeout"`
+		Since   time.Time     `query:"since,omitempty" layout:"2006-01-02"`
+		Region  *string       `query:"region"`
+		Level   *int8         `query:"level,omitempty"`
+		IP      net.IP        `query:"ip,omitempty"`
+		Range   dateRange     `query:"range,omitempty"`
+		Ignored string        `query:"-"`
+		Other   string
+	}
+	tests := []struct {
+		name    string
+		v       any
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Zero values",
+			v:    target{},
+			want: "page=0&limit=0&q=&ratio=0&timeout=0s",
+		},
+		{
+			name: "All fields in order",
+			v: &target{
+				encodePagination: encodePagination{Page: 2, Limit: 10},
+				DecodeSorting:    &DecodeSorting{Sort: "name"},
+				Query:            "a testing query",
+				Tags:             []string{"a", "b"},
+				IDs:              []int64{1, 2},
+				Ratio:            0.1,
+				Debug:            true,
+				Timeout:          90 * time.Second,
+				Since:            time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+				Region:           ptr("eu"),
+				Level:            ptr[int8](-3),
+				IP:               net.IPv4(127, 0, 0, 1),
+				Range:            dateRange{From: "2024-01-01", To: "2024-02-01"},
+				Ignored:          "x",
+				Other:            "y",
+			},
+			want: "page=2&limit=10&sort=name&q=a+testing+query&tag=a&tag=b&id=1&id=2&ratio=0.1&debug=true&timeout=1m30s" +
+				"&since=2024-05-01&region=eu&level=-3&ip=127.0.0.1&range_from=2024-01-01&range_to=2024-02-01",
+		},
+		{
+			name: "Marshaler error",
+			v: struct {
+				Range dateRange `query:"range"`
+			}{},
+			wantErr: true,
+		},
+		{
+			name: "Unsupported type",
+			v: struct {
+				Map map[string]string `query:"map"`
+			}{Map: map[string]string{}},
+			wantErr: true,
+		},
+		{
+			name:    "Not a struct",
+			v:       "q=1",
+			wantErr: true,
+		},
+		{
+			name:    "Nil pointer",
+			v:       (*target)(nil),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Marshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if encoded := got.Encode(); encoded != tt.want {
+				t.Errorf("Marshal() = %q, want %q", encoded, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	_, err := Marshal(struct {
+		Map map[string]string `query:"map"`
+	}{})
+	if !errors.Is(err, ErrUnsupportedType) || !strings.HasPrefix(err.Error(), "urlqm: cannot encode field Map: unsupported field type") {
+		t.Errorf("Marshal() error = %v, want ErrUnsupportedType for field Map", err)
+	}
+	if _, err := Marshal(42); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Marshal() error = %v, want ErrInvalidValue", err)
+	}
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	want := decodeTarget{
+		decodePagination: decodePagination{Page: 2},
+		DecodeSorting:    &DecodeSorting{Sort: "name"},
+		Query:            "a & b = c",
+		Tags:             []string{"a", "b"},
+		IDs:              []int64{1, -2},
+		Ratio:            1.5,
+		Debug:            true,
+		Timeout:          time.Minute,
+		Since:            time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Until:            time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Region:           ptr("Київ"),
+		Level:            ptr[int8](7),
+		IP:               net.IPv4(10, 0, 0, 1),
+		Limit:            "ten",
+	}
+	params, err := Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var got decodeTarget
+	if err := Unmarshal(params.Encode(), &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal(Marshal()) = %+v, want %+v", got, want)
+	}
+}
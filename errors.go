@@ -0,0 +1,250 @@
+package urlqm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrEmptyKey is returned by a strict [Parser] for a param that has a value but no key, like `=value`.
+	ErrEmptyKey = errors.New("urlqm: empty key")
+
+	// ErrInvalidTarget is returned by [Unmarshal] and [Params.Decode] if the target is not a non-nil pointer to a struct.
+	ErrInvalidTarget = errors.New("urlqm: decode target must be a non-nil pointer to a struct")
+
+	// ErrInvalidValue is returned by [Marshal] if the value is not a struct or a non-nil pointer to a struct.
+	ErrInvalidValue = errors.New("urlqm: marshal value must be a struct or a non-nil pointer to a struct")
+
+	// ErrNestedConflict is reported for a param in bracket notation that conflicts with the other params,
+	// like `a[b]=c` after `a=d`, which would make `a` both a value and an object.
+	ErrNestedConflict = errors.New("urlqm: conflicting nested parameter")
+
+	// ErrUnsupportedStyle is returned for a combination of an OpenAPI style and explode that is not defined
+	// for the kind of a param, see [Params.SetStyled] and [Params.SetStyledObject].
+	ErrUnsupportedStyle = errors.New("urlqm: unsupported parameter style")
+
+	// ErrMalformedStyled is returned by [Params.GetStyledObject] for a value that can't be split into fields.
+	ErrMalformedStyled = errors.New("urlqm: malformed styled parameter")
+
+	// ErrUnsupportedType is wrapped by the errors of [Params.Decode] and [Marshal]
+	// for a struct field of a type that can't be decoded or encoded.
+	ErrUnsupportedType = errors.New("urlqm: unsupported field type")
+
+	// ErrNoSigningKey is returned by [Signer.Sign] if the signer has no key.
+	ErrNoSigningKey = errors.New("urlqm: no signing key")
+
+	// ErrUnsigned is returned by [Signer.Verify] for a query without a signature param.
+	ErrUnsigned = errors.New("urlqm: query is not signed")
+
+	// ErrUnknownKeyID is returned by [Signer.Verify] if the signer has no key with the key ID of the query.
+	ErrUnknownKeyID = errors.New("urlqm: unknown signing key ID")
+
+	// ErrInvalidSignature is returned by [Signer.Verify] if the signature doesn't match the query.
+	ErrInvalidSignature = errors.New("urlqm: invalid signature")
+
+	// ErrSignatureExpired is returned by [Signer.Verify] for a validly signed query whose expiry time has passed.
+	ErrSignatureExpired = errors.New("urlqm: signature expired")
+
+	// ErrInvalidTemplate is returned by [ParseQueryTemplate] for a malformed URI template,
+	// or for a template with expressions other than the query ones.
+	ErrInvalidTemplate = errors.New("urlqm: invalid query template")
+
+	// ErrTemplateValue is returned by [QueryTemplate.Expand] for a variable value that can't be expanded.
+	ErrTemplateValue = errors.New("urlqm: invalid template variable value")
+
+	// ErrParamRequired, ErrParamUnknown, ErrParamCount, ErrParamPattern, ErrParamEnum, ErrParamRange
+	// and ErrParamExclusive are wrapped by the errors of [Schema.Validate], one for every kind of violation.
+	ErrParamRequired  = errors.New("urlqm: parameter is required")
+	ErrParamUnknown   = errors.New("urlqm: parameter is not allowed")
+	ErrParamCount     = errors.New("urlqm: wrong number of parameter occurrences")
+	ErrParamPattern   = errors.New("urlqm: value doesn't match the pattern")
+	ErrParamEnum      = errors.New("urlqm: value is not allowed")
+	ErrParamRange     = errors.New("urlqm: value is out of range")
+	ErrParamExclusive = errors.New("urlqm: parameters are mutually exclusive")
+)
+
+// ParamPart tells which part of a param failed to be parsed.
+type ParamPart int
+
+const (
+	// PartKey is the key of a param.
+	PartKey ParamPart = iota
+	// PartValue is the value of a param.
+	PartValue
+)
+
+func (p ParamPart) String() string {
+	if p == PartKey {
+		return "key"
+	}
+	return "value"
+}
+
+// ParseError describes a param that failed to be parsed.
+// It wraps the underlying error, usually a `url.EscapeError`, which can be checked with [errors.As].
+type ParseError struct {
+	// Offset is the byte offset of the param in the query string.
+	Offset int
+	// Index is the position of the param among the params of the query string, starting from 0.
+	Index int
+	// Key and Value are the raw (still escaped) key and value of the param.
+	Key, Value string
+	// Part tells whether the key or the value of the param failed.
+	Part ParamPart
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("urlqm: parameter %q at offset %d has invalid %s: %v", e.Key, e.Offset, e.Part, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors is a list of errors that occurred while parsing a query string, in order of their appearance.
+// It can be inspected with [errors.Is] and [errors.As], or ranged over to get every [ParseError].
+type ParseErrors []*ParseError
+
+func (e ParseErrors) Error() string {
+	return joinErrors(e)
+}
+
+func (e ParseErrors) Unwrap() []error {
+	return unwrapErrors(e)
+}
+
+// err returns the list as an error, or nil if the list is empty.
+func (e ParseErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// DecodeError describes a struct field that failed to be decoded from a param.
+type DecodeError struct {
+	// Field is the name of the struct field, fields of embedded structs are prefixed with the struct name,
+	// like `Pagination.Page`.
+	Field string
+	// Key and Value are the decoded key and value of the param.
+	Key, Value string
+	// Err is the underlying error, like a [*strconv.NumError] or a [*time.ParseError].
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("urlqm: cannot decode parameter %q value %q into field %s: %s", e.Key, e.Value, e.Field, causeString(e.Err))
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeErrors is a list of struct fields that failed to be decoded, in the order of the fields.
+// Like [ParseErrors], it is a list of the underlying errors.
+type DecodeErrors []*DecodeError
+
+func (e DecodeErrors) Error() string {
+	return joinErrors(e)
+}
+
+func (e DecodeErrors) Unwrap() []error {
+	return unwrapErrors(e)
+}
+
+// ConversionError describes a param whose value failed to be converted by a typed getter,
+// like [GetQueryParamInt] or [Params.GetInt].
+type ConversionError struct {
+	// Key and Value are the decoded key and value of the param.
+	Key, Value string
+	// Err is the underlying error, like a [*strconv.NumError] or a [*time.ParseError].
+	Err error
+}
+
+func (e *ConversionError) Error() string {
+	return fmt.Sprintf("urlqm: cannot convert parameter %q value %q: %v", e.Key, e.Value, e.Err)
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError describes a violation of a [Schema].
+type ValidationError struct {
+	// Key is the key of the param.
+	Key string
+	// Pos is the position of the violating param among the params, starting from 0,
+	// or -1 if the violation is about an absent param, like a missing required one.
+	Pos int
+	// Value is the value of the violating param.
+	Value string
+	// Err tells what is wrong, it wraps one of [ErrParamRequired], [ErrParamUnknown], [ErrParamCount],
+	// [ErrParamPattern], [ErrParamEnum], [ErrParamRange] and [ErrParamExclusive].
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Pos < 0 {
+		return fmt.Sprintf("urlqm: parameter %q: %s", e.Key, causeString(e.Err))
+	}
+	return fmt.Sprintf("urlqm: parameter %q at position %d: %s", e.Key, e.Pos, causeString(e.Err))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors is a list of violations of a [Schema], see [Schema.Validate].
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	return joinErrors(e)
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	return unwrapErrors(e)
+}
+
+// joinErrors returns the messages of the errors of a list, separated by "; ".
+func joinErrors[E error](errs []E) string {
+	var buf strings.Builder
+	for i, err := range errs {
+		if i > 0 {
+			buf.WriteString("; ")
+		}
+		buf.WriteString(err.Error())
+	}
+	return buf.String()
+}
+
+// unwrapErrors returns the errors of a list for [errors.Is] and [errors.As].
+func unwrapErrors[E error](errs []E) []error {
+	unwrapped := make([]error, len(errs))
+	for i, err := range errs {
+		unwrapped[i] = err
+	}
+	return unwrapped
+}
+
+// causeString returns the message of an underlying error without the "urlqm: " prefix,
+// which the message of the wrapping error already has.
+func causeString(err error) string {
+	return strings.TrimPrefix(err.Error(), "urlqm: ")
+}
+
+// wrapError returns an error that wraps err, with a message made of the formatted context and the message of err.
+func wrapError(err error, format string, args ...any) error {
+	return &wrappedError{msg: fmt.Sprintf(format, args...) + ": " + causeString(err), err: err}
+}
+
+type wrappedError struct {
+	msg string
+	err error
+}
+
+func (e *wrappedError) Error() string { return e.msg }
+
+func (e *wrappedError) Unwrap() error { return e.err }
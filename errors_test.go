@@ -0,0 +1,94 @@
+package urlqm
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseParamsErrors(t *testing.T) {
+	query := "a=1&q=100%+truth&&b%zz=2&brightness=90%"
+	_, err := ParseParams(query)
+
+	var errs ParseErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("ParseParams() error = %v, want ParseErrors", err)
+	}
+	want := ParseErrors{
+		{Offset: 4, Index: 1, Key: "q", Value: "100%+truth", Part: PartValue, Err: url.EscapeError("%+t")},
+		{Offset: 18, Index: 2, Key: "b%zz", Value: "2", Part: PartKey, Err: url.EscapeError("%zz")},
+		{Offset: 25, Index: 3, Key: "brightness", Value: "90%", Part: PartValue, Err: url.EscapeError("%")},
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("ParseParams() errors = %v, want %v", errs, want)
+	}
+
+	var escapeErr url.EscapeError
+	if !errors.As(err, &escapeErr) || escapeErr != "%+t" {
+		t.Errorf("errors.As(url.EscapeError) = %q, want %q", escapeErr, "%+t")
+	}
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr != errs[0] {
+		t.Errorf("errors.As(*ParseError) = %v, want %v", parseErr, errs[0])
+	}
+
+	wantMsg := `urlqm: parameter "brightness" at offset 25 has invalid value: invalid URL escape "%"`
+	if msg := errs[2].Error(); msg != wantMsg {
+		t.Errorf("ParseError.Error() = %v, want %v", msg, wantMsg)
+	}
+}
+
+func TestParseParamsNoErrors(t *testing.T) {
+	// a nil error must not be a typed nil.
+	if _, err := ParseParams("a=1&b=2"); err != nil {
+		t.Errorf("ParseParams() error = %v, want nil", err)
+	}
+}
+
+func TestParser_StrictParseError(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  *ParseError
+	}{
+		{
+			name:  "Bad value",
+			query: "a=1;b=2&c=%",
+			want:  &ParseError{Offset: 8, Index: 2, Key: "c", Value: "%", Part: PartValue, Err: url.EscapeError("%")},
+		},
+		{
+			name:  "Empty key",
+			query: "a=1&=2",
+			want:  &ParseError{Offset: 4, Index: 1, Key: "", Value: "2", Part: PartKey, Err: ErrEmptyKey},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parser{Strict: true}.ParseParams(tt.query)
+			var got *ParseError
+			if !errors.As(err, &got) {
+				t.Fatalf("Parser.ParseParams() error = %v, want *ParseError", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parser.ParseParams() error = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryParamParseError(t *testing.T) {
+	query := "a=1&&b=2;brightness=90%"
+	want := &ParseError{Offset: 9, Index: 2, Key: "brightness", Value: "90%", Part: PartValue, Err: url.EscapeError("%")}
+
+	_, err := GetQueryParam(query, "brightness")
+	var got *ParseError
+	if !errors.As(err, &got) || !reflect.DeepEqual(got, want) {
+		t.Errorf("GetQueryParam() error = %+v, want %+v", err, want)
+	}
+
+	_, err = ExtractQueryParamAll(&query, "brightness")
+	if !errors.As(err, &got) || !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractQueryParamAll() error = %+v, want %+v", err, want)
+	}
+}
@@ -0,0 +1,86 @@
+package urlqm
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Escaper escapes keys and values of query params.
+type Escaper interface {
+	Escape(s string) string
+}
+
+// EscaperFunc is an adapter to use an ordinary function as an [Escaper].
+type EscaperFunc func(s string) string
+
+// Escape calls f(s).
+func (f EscaperFunc) Escape(s string) string {
+	return f(s)
+}
+
+var (
+	// FormEscaper escapes keys and values like `url.QueryEscape` does (form encoding), a space becomes '+'.
+	// It is used by default.
+	FormEscaper Escaper = EscaperFunc(url.QueryEscape)
+
+	// RFC3986Escaper leaves only unreserved characters (RFC 3986, section 2.3) unescaped,
+	// so a space becomes "%20" and '+' is always escaped.
+	RFC3986Escaper Escaper = SafeSetEscaper("")
+)
+
+// SafeSetEscaper escapes like [RFC3986Escaper], but it also leaves the characters of the set unescaped,
+// e.g. `SafeSetEscaper(":/,@")` keeps urls, lists and emails readable.
+// The set must contain only ASCII characters, and it must not contain '%', '=' and the param separators,
+// otherwise the encoded query can't be parsed back.
+type SafeSetEscaper string
+
+// Escape escapes s, leaving unreserved characters and the characters of the set as they are.
+func (safe SafeSetEscaper) Escape(s string) string {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if !safe.keep(s[i]) {
+			n++
+		}
+	}
+	if n == 0 {
+		return s
+	}
+
+	const upperhex = "0123456789ABCDEF"
+	var buf strings.Builder
+	buf.Grow(len(s) + 2*n)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if safe.keep(c) {
+			buf.WriteByte(c)
+			continue
+		}
+		buf.WriteByte('%')
+		buf.WriteByte(upperhex[c>>4])
+		buf.WriteByte(upperhex[c&15])
+	}
+	return buf.String()
+}
+
+func (safe SafeSetEscaper) keep(c byte) bool {
+	return isUnreserved(c) || (c < 0x80 && strings.IndexByte(string(safe), c) >= 0)
+}
+
+// isUnreserved reports whether c is an unreserved character according to RFC 3986, section 2.3.
+func isUnreserved(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	case c == '-', c == '.', c == '_', c == '~':
+		return true
+	}
+	return false
+}
+
+// escaperOrDefault returns e, or [FormEscaper] if e is nil.
+func escaperOrDefault(e Escaper) Escaper {
+	if e == nil {
+		return FormEscaper
+	}
+	return e
+}
@@ -0,0 +1,78 @@
+package urlqm
+
+import (
+	"testing"
+)
+
+func TestEscapers(t *testing.T) {
+	tests := []struct {
+		name    string
+		escaper Escaper
+		s       string
+		want    string
+	}{
+		{name: "Form", escaper: FormEscaper, s: "a b+c/d~", want: "a+b%2Bc%2Fd~"},
+		{name: "RFC 3986", escaper: RFC3986Escaper, s: "a b+c/d~-._", want: "a%20b%2Bc%2Fd~-._"},
+		{name: "RFC 3986 non-ASCII", escaper: RFC3986Escaper, s: "ключ", want: "%D0%BA%D0%BB%D1%8E%D1%87"},
+		{name: "RFC 3986 nothing to escape", escaper: RFC3986Escaper, s: "abc-123", want: "abc-123"},
+		{name: "Safe set", escaper: SafeSetEscaper(":/,@"), s: "https://x.y/a b,c@d", want: "https://x.y/a%20b,c@d"},
+		{name: "Safe set ignores non-ASCII", escaper: SafeSetEscaper("ї"), s: "ї", want: "%D1%97"},
+		{
+			name:    "Func",
+			escaper: EscaperFunc(func(s string) string { return "<" + s + ">" }),
+			s:       "a",
+			want:    "<a>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.escaper.Escape(tt.s); got != tt.want {
+				t.Errorf("Escape() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeParamsWith(t *testing.T) {
+	params := []Param{{Key: "q", Value: "a b+c"}, {Key: "list", Value: "1,2"}, {Key: "debug", Flag: true}}
+	tests := []struct {
+		name    string
+		escaper Escaper
+		want    string
+	}{
+		{name: "Default", escaper: nil, want: "q=a+b%2Bc&list=1%2C2&debug"},
+		{name: "RFC 3986", escaper: RFC3986Escaper, want: "q=a%20b%2Bc&list=1%2C2&debug"},
+		{name: "Safe set", escaper: SafeSetEscaper(","), want: "q=a%20b%2Bc&list=1,2&debug"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EncodeParamsWith(params, tt.escaper); got != tt.want {
+				t.Errorf("EncodeParamsWith() = %v, want %v", got, tt.want)
+			}
+			p := Params(params)
+			if got := p.EncodeWith(tt.escaper); got != tt.want {
+				t.Errorf("Params.EncodeWith() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_Escaper(t *testing.T) {
+	p := Parser{Escaper: SafeSetEscaper(",")}
+
+	query := "a=1"
+	p.AddQueryParam(&query, "list", "x y,z", "1,2")
+	if want := "a=1&list=x%20y,z&list=1,2"; query != want {
+		t.Errorf("Parser.AddQueryParam() query = %v, want %v", query, want)
+	}
+
+	p.SetQueryParam(&query, "list", "3,4")
+	if want := "a=1&list=3,4"; query != want {
+		t.Errorf("Parser.SetQueryParam() query = %v, want %v", query, want)
+	}
+
+	p.SetQueryFlag(&query, "a b")
+	if want := "a=1&list=3,4&a%20b"; query != want {
+		t.Errorf("Parser.SetQueryFlag() query = %v, want %v", query, want)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build go1.23
+
+package urlqm
+
+import "fmt"
+
+func ExampleQueryParams() {
+	for key, value := range QueryParams("q=a+testing+query&region=1&debug&region=%D0%9A%D0%B8%D1%97%D0%B2") {
+		fmt.Printf("%s: %q\n", key, value)
+	}
+	// Output:
+	// q: "a testing query"
+	// region: "1"
+	// debug: ""
+	// region: "Київ"
+}
+
+func ExampleParams_Keys() {
+	params, _ := ParseQuery("a=1&b=2&a=3")
+	for key := range params.Keys() {
+		fmt.Println(key, params.GetAll(key))
+	}
+	// Output:
+	// a [1 3]
+	// b [2]
+}
@@ -0,0 +1,41 @@
+package urlqm
+
+import "fmt"
+
+func ExampleParseNested() {
+	root, err := ParseNested("filter[status]=open&filter[tags][]=go&filter[tags][]=url&items[0][id]=5&items[1][id]=6")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(root.Find("filter", "status").Value)
+	fmt.Println(root.Find("filter", "tags").Interface())
+	fmt.Println(root.Find("items", "1", "id").Value)
+
+	root.Get("filter").Set("status", &Node{Value: "closed"})
+	params := NestedParser{Indices: true}.Encode(root)
+	fmt.Println(params.EncodeWith(SafeSetEscaper("[]")))
+	// Output:
+	// open
+	// [go url]
+	// 6
+	// filter[status]=closed&filter[tags][0]=go&filter[tags][1]=url&items[0][id]=5&items[1][id]=6
+}
+
+func ExampleUnmarshalNested() {
+	type Filter struct {
+		Status string   `query:"status"`
+		Tags   []string `query:"tags"`
+	}
+	var query struct {
+		Filter Filter `query:"filter"`
+		Items  []struct {
+			ID int `query:"id"`
+		} `query:"items"`
+	}
+	err := UnmarshalNested("filter[status]=open&filter[tags][]=go&items[][id]=5&items[][id]=6", &query)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%+v\n", query)
+	// Output: {Filter:{Status:open Tags:[go]} Items:[{ID:5} {ID:6}]}
+}
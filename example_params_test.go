@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"time"
 )
 
 func ExampleParseParams() {
@@ -39,3 +40,175 @@ func ExampleSortOrderParams() {
 	// Output: Query: a=1&page=1&q=100+truth&c=3&b=2
 
 }
+
+func ExampleParser_ParseParams() {
+	// ';' is a part of the value, and '+' stays a plus.
+	p := Parser{Separators: "&", KeepPlus: true}
+	params, err := p.ParseParams("path=a;b&expr=1+2")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%q %q\n", params[0].Value, params[1].Value)
+	// Output: "a;b" "1+2"
+}
+
+func ExampleParseErrors() {
+	_, err := ParseParams(`a=1&q=100%+truth&b=2&brightness=90%`)
+
+	var errs ParseErrors
+	if errors.As(err, &errs) {
+		for _, e := range errs {
+			fmt.Printf("parameter %q (#%d) at offset %d has invalid %s\n", e.Key, e.Index, e.Offset, e.Part)
+		}
+	}
+	// Output:
+	// parameter "q" (#1) at offset 4 has invalid value
+	// parameter "brightness" (#3) at offset 21 has invalid value
+}
+
+func ExampleUnmarshal() {
+	type Pagination struct {
+		Page  int `query:"page"`
+		Limit int `query:"limit"`
+	}
+	var filter struct {
+		Pagination
+		Query  string    `query:"q"`
+		Tags   []string  `query:"tag"`
+		Since  time.Time `query:"since" layout:"2006-01-02"`
+		Strict *bool     `query:"strict"`
+	}
+
+	err := Unmarshal("q=a+testing+query&tag=go&tag=url&since=2024-05-01&page=2&limit=x", &filter)
+
+	var errs DecodeErrors
+	if errors.As(err, &errs) {
+		for _, e := range errs {
+			fmt.Printf("%s: %q is invalid\n", e.Field, e.Value)
+		}
+	}
+	fmt.Println(filter.Query, filter.Tags, filter.Since.Format(time.DateOnly), filter.Page, filter.Strict == nil)
+	// Output:
+	// Pagination.Limit: "x" is invalid
+	// a testing query [go url] 2024-05-01 2 true
+}
+
+func ExampleMarshal() {
+	type Search struct {
+		Query string   `query:"q"`
+		Tags  []string `query:"tag"`
+		Page  int      `query:"page,omitempty"`
+		Lang  *string  `query:"lang"`
+	}
+	params, err := Marshal(Search{Query: "a testing query", Tags: []string{"go", "url"}})
+	if err != nil {
+		panic(err)
+	}
+	params.Add("sig", "abc")
+	fmt.Println(params.Encode())
+	// Output: q=a+testing+query&tag=go&tag=url&sig=abc
+}
+
+func ExampleParamsFromURL() {
+	u, err := url.Parse("https://example.com/search?q=a+testing+query&page=1&sort=date")
+	if err != nil {
+		panic(err)
+	}
+	params, err := ParamsFromURL(u)
+	if err != nil {
+		panic(err)
+	}
+	params.Set("page", "2")
+	params.ApplyTo(u)
+	fmt.Println(u)
+
+	values := url.Values{"b": {"2"}, "a": {"1"}, "q": {"query"}}
+	params = FromValues(values, "q")
+	fmt.Println(params.Encode())
+	// Output:
+	// https://example.com/search?q=a+testing+query&page=2&sort=date
+	// q=query&a=1&b=2
+}
+
+func ExampleCanonicalQuerySigV4() {
+	params, err := ParseQuery("prefix=photos/2024&max-keys=20&list-type=2&marker=a+b")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println(CanonicalQuerySigV4(params))
+	// Output: list-type=2&marker=a%20b&max-keys=20&prefix=photos%2F2024
+}
+
+func ExampleDiff() {
+	before, _ := ParseQuery("q=go&page=1&tag=url&tag=query&debug")
+	after, _ := ParseQuery("page=2&q=go&tag=url&lang=en")
+	patch := Diff(before, after)
+	fmt.Print(patch)
+
+	query := "q=go&page=1&tag=url&tag=query&debug"
+	patch.ApplyToQuery(&query)
+	fmt.Println(query)
+	// Output:
+	// remove "tag"#1 "query" at 3
+	// remove "debug"#0 (flag) at 4
+	// change "page"#0 "1" -> "2" at 0
+	// move "page"#0 at 0
+	// add "lang"#0 "en" at 3
+	// page=2&q=go&tag=url&lang=en
+}
+
+func ExampleQueryTemplate() {
+	tmpl, err := ParseQueryTemplate("/orders{?status,page,sort*}")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	link, err := tmpl.ExpandURI(map[string]any{
+		"status": []string{"new", "paid"},
+		"page":   "2",
+		"sort":   []Param{{Key: "by", Value: "date"}, {Key: "order", Value: "desc"}},
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println(link)
+
+	vars, err := tmpl.Extract("status=new&page=3")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println(vars["status"], vars["page"])
+	// Output:
+	// /orders?status=new,paid&page=2&by=date&order=desc
+	// new 3
+}
+
+func ExampleSchema() {
+	schema := &Schema{
+		Params: []ParamSchema{
+			{Key: "q", Required: true, MaxCount: 1},
+			{Key: "page", MaxCount: 1, Range: &NumberRange{Min: 1, Max: 100}},
+			{Key: "sort", Enum: []string{"date", "name"}},
+			{Key: "cursor"},
+		},
+		RejectUnknown: true,
+		Exclusive:     [][]string{{"page", "cursor"}},
+	}
+	err := schema.ValidateQuery("page=0&sort=size&cursor=abc&debug")
+
+	var errs ValidationErrors
+	if errors.As(err, &errs) {
+		for _, err := range errs {
+			fmt.Println(err)
+		}
+	}
+	// Output:
+	// urlqm: parameter "page" at position 0: value is out of range: "0", want a number in [1, 100]
+	// urlqm: parameter "sort" at position 1: value is not allowed: "size", want one of ["date" "name"]
+	// urlqm: parameter "cursor" at position 2: parameters are mutually exclusive: "page" and "cursor"
+	// urlqm: parameter "debug" at position 3: parameter is not allowed
+	// urlqm: parameter "q": parameter is required
+}
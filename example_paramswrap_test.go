@@ -14,9 +14,13 @@ func ExampleParseQuery() {
 	if err != nil {
 		fmt.Println("Error:", err)
 	}
-	fmt.Printf("%+v\n", params)
+	for _, param := range params {
+		fmt.Printf("%s: %s\n", param.Key, param.Value)
+	}
 	// Output:
-	// [{Key:q Value:100% truth} {Key:a Value:1} {Key:b Value:2}]
+	// q: 100% truth
+	// a: 1
+	// b: 2
 }
 
 func ExampleParams_Encode() {
@@ -24,7 +28,7 @@ func ExampleParams_Encode() {
 	if err != nil {
 		panic(err)
 	}
-	params := Params{{"q", "100% truth"}, {"a", "1"}, {"b", "2"}}
+	params := Params{{Key: "q", Value: "100% truth"}, {Key: "a", Value: "1"}, {Key: "b", Value: "2"}}
 
 	u.RawQuery = params.Encode()
 
@@ -214,3 +218,27 @@ func ExampleParams_Has() {
 	// true
 	// false
 }
+
+func ExampleParams_EncodeWith() {
+	params := Params{{Key: "q", Value: "a b"}, {Key: "fields", Value: "id,name"}, {Key: "next", Value: "https://example.com/"}}
+
+	fmt.Println(params.EncodeWith(RFC3986Escaper))
+	fmt.Println(params.EncodeWith(SafeSetEscaper(":/,")))
+	// Output:
+	// q=a%20b&fields=id%2Cname&next=https%3A%2F%2Fexample.com%2F
+	// q=a%20b&fields=id,name&next=https://example.com/
+}
+
+func ExampleParams_SetStyled() {
+	var params Params
+	_ = params.SetStyled("ids", []string{"3", "4", "5"}, StyleForm, false)
+	_ = params.SetStyled("tags", []string{"go", "url"}, StylePipeDelimited, false)
+	_ = params.SetStyledObject("color", []Param{{Key: "R", Value: "100"}, {Key: "G", Value: "200"}}, StyleDeepObject, true)
+	fmt.Println(params.Encode())
+
+	ids, _ := params.GetStyled("ids", StyleForm, false)
+	fmt.Println(ids)
+	// Output:
+	// ids=3,4,5&tags=go|url&color[R]=100&color[G]=200
+	// [3 4 5]
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"net/url"
+	"strings"
+	"time"
 )
 
 func ExampleGetQueryParam() {
@@ -138,3 +140,116 @@ func ExampleHasQueryParam() {
 	// a: true
 	// c: false
 }
+
+func ExampleParser_GetQueryParam() {
+	// Keys are compared in decoded form, so they don't have to be escaped,
+	// and they match regardless of the hex case or the way a space was encoded.
+	p := Parser{DecodeKeys: true}
+	query := "a=1&%d0%ba%d0%bb%d1%8e%d1%87=%D0%B7%D0%BD%D0%B0%D1%87%D0%B5%D0%BD%D0%BD%D1%8F&full+name=John%20Doe"
+
+	val, err := p.GetQueryParam(query, "ключ")
+	if err != nil {
+		fmt.Println("Error:", err)
+	}
+	fmt.Println(val)
+
+	val, err = p.GetQueryParam(query, "full name")
+	if err != nil {
+		fmt.Println("Error:", err)
+	}
+	fmt.Println(val)
+	// Output:
+	// значення
+	// John Doe
+}
+
+func ExampleQueryScanner() {
+	buf := make([]byte, 0, 64)
+	s := NewQueryScanner("q=a+testing+query&region=1&debug&region=%D0%9A%D0%B8%D1%97%D0%B2")
+	for s.Next() {
+		if s.RawKey() != "region" {
+			continue
+		}
+		var err error
+		if buf, err = s.AppendValue(buf[:0]); err != nil {
+			fmt.Println("Error:", err)
+			continue
+		}
+		fmt.Println(string(buf))
+	}
+	if err := s.Err(); err != nil {
+		fmt.Println("Error:", err)
+	}
+	// Output:
+	// 1
+	// Київ
+}
+
+func ExampleStripTracking() {
+	query := "utm_source=newsletter&q=a+testing+query&fbclid=IwAR2F4&ref=home&page=2"
+	StripTracking(&query, ExactRule("ref"))
+	fmt.Println(query)
+	// Output: q=a+testing+query&page=2
+}
+
+func ExampleDeleteQueryParams() {
+	query := "utm_source=newsletter&q=a+testing+query&ref=home&page=2&ref=nav"
+	DeleteQueryParams(&query, "utm_source", "ref")
+	fmt.Println(query)
+
+	query = "utm_source=newsletter&q=a+testing+query&session_id=7&page=2&debug"
+	KeepQueryParams(&query, "q", "page")
+	fmt.Println(query)
+
+	query = "utm_source=newsletter&q=a+testing+query&session_id=7&page=2&debug"
+	DeleteQueryParamsFunc(&query, GlobRule("utm_*", "*_id").Match)
+	fmt.Println(query)
+	// Output:
+	// q=a+testing+query&page=2
+	// q=a+testing+query&page=2
+	// q=a+testing+query&page=2&debug
+}
+
+func ExampleCanonicalize() {
+	fmt.Println(Canonicalize("b=2;a=%7e%41&c=&b=1&a=~A"))
+	fmt.Println(Canonicalize("utm_source=x&q=%47o;page=2", NormalizeEncoding, Denylist(TrackingRules), SortKeys))
+	// Output:
+	// a=~A&b=2&b=1
+	// page=2&q=Go
+}
+
+func ExampleSigner() {
+	signer := Signer{Key: []byte("secret"), KeyID: "2025"}
+	query, err := signer.Sign("file=report.pdf&user=42", time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println(query)
+	fmt.Println(signer.Verify(query))
+	fmt.Println(signer.Verify(strings.Replace(query, "user=42", "user=43", 1)))
+	// Output:
+	// file=report.pdf&user=42&expires=4102444800&kid=2025&signature=DrvXdkMEOmLzvp_L5SryPtItosOi0RULc6kyY6cO5gk
+	// <nil>
+	// urlqm: invalid signature
+}
+
+func ExampleGetQueryParamInt() {
+	query := "page=2&limit=ten&debug"
+	page, found, err := GetQueryParamInt(query, "page")
+	fmt.Println(page, found, err)
+
+	_, found, err = GetQueryParamInt(query, "limit")
+	fmt.Println(found, err)
+
+	debug, _, _ := GetQueryParamBool(query, "debug")
+	fmt.Println(debug)
+
+	SetQueryParamInt(&query, "page", page+1)
+	fmt.Println(query)
+	// Output:
+	// 2 true <nil>
+	// true urlqm: cannot convert parameter "limit" value "ten": strconv.Atoi: parsing "ten": invalid syntax
+	// true
+	// page=3&limit=ten&debug
+}
@@ -0,0 +1,110 @@
+package urlqm
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// structField describes a struct field mapped to a query param by its `query` tag.
+type structField struct {
+	// key is the param key.
+	key string
+	// name is the path to the field from the outer struct, like `Filter.Page`.
+	name string
+	// index is the index sequence for [reflect.Value.FieldByIndex].
+	index []int
+	typ   reflect.Type
+	// layout is the time layout of the field, taken from the `layout` tag.
+	layout    string
+	omitEmpty bool
+	depth     int
+}
+
+var fieldCache sync.Map // map[reflect.Type][]structField
+
+// cachedFields returns the query fields of the struct type t, in the order of declaration.
+// Fields of embedded structs without a `query` tag are promoted, like encoding/json does;
+// if several fields have the same key, the least nested one wins.
+func cachedFields(t reflect.Type) []structField {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]structField)
+	}
+	fields, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return fields.([]structField)
+}
+
+func typeFields(t reflect.Type) []structField {
+	var fields []structField
+	collectFields(t, nil, "", 0, &fields, map[reflect.Type]bool{})
+
+	// drop the fields that are shadowed by less nested ones.
+	depths := make(map[string]int, len(fields))
+	for _, f := range fields {
+		if d, ok := depths[f.key]; !ok || f.depth < d {
+			depths[f.key] = f.depth
+		}
+	}
+	result := fields[:0]
+	seen := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if f.depth != depths[f.key] || seen[f.key] {
+			continue
+		}
+		seen[f.key] = true
+		result = append(result, f)
+	}
+	return result
+}
+
+func collectFields(t reflect.Type, index []int, prefix string, depth int, fields *[]structField, visited map[reflect.Type]bool) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+	defer delete(visited, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, hasTag := sf.Tag.Lookup("query")
+		if tag == "-" {
+			continue
+		}
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		if sf.Anonymous && !hasTag {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectFields(ft, fieldIndex, prefix+sf.Name+".", depth+1, fields, visited)
+				continue
+			}
+		}
+		if !hasTag || !sf.IsExported() {
+			continue
+		}
+
+		key, opts, _ := strings.Cut(tag, ",")
+		if key == "" {
+			key = sf.Name
+		}
+		f := structField{
+			key:    key,
+			name:   prefix + sf.Name,
+			index:  fieldIndex,
+			typ:    sf.Type,
+			layout: sf.Tag.Get("layout"),
+			depth:  depth,
+		}
+		for opts != "" {
+			var opt string
+			opt, opts, _ = strings.Cut(opts, ",")
+			if opt == "omitempty" {
+				f.omitEmpty = true
+			}
+		}
+		*fields = append(*fields, f)
+	}
+}
@@ -1,39 +1,201 @@
 package urlqm
 
 import (
-	"fmt"
 	"strings"
 )
 
-var paramSep = "&"
-var deprecatedParamSep = ";"
-var separators = "&;"
+// defaultSeparators are the param separators of a query string if no others are configured.
+const defaultSeparators = "&;"
 
-func cutStringByAnySep(s string, seps string) (string, string) {
+// indexParam returns the bounds of the first param in query, starting at offset from,
+// which has exactly the given key. A key matches only at the beginning of the query
+// or right after a separator, and only if it is followed by '=', a separator or the end of the query,
+// so valueless params (flags) are matched as well.
+// If there is no such param, it returns -1, -1.
+func indexParam(query, key, seps string, from int) (start, end int) {
+	if key == "" {
+		return -1, -1
+	}
+	for from < len(query) {
+		i := strings.Index(query[from:], key)
+		if i < 0 {
+			break
+		}
+		start = from + i
+		end = start + len(key)
+		if start == 0 || isSeparator(query[start-1], seps) {
+			if end == len(query) || isSeparator(query[end], seps) {
+				return start, end
+			}
+			if query[end] == '=' {
+				if j := strings.IndexAny(query[end:], seps); j >= 0 {
+					return start, end + j
+				}
+				return start, len(query)
+			}
+		}
+		from = start + 1
+	}
+	return -1, -1
+}
+
+// indexParamDecoded is like indexParam, but it compares keys in decoded form,
+// so the given key must be unescaped. If plusAsSpace is true, '+' in the query is decoded as a space.
+func indexParamDecoded(query, key, seps string, plusAsSpace bool, from int) (start, end int) {
+	if key == "" {
+		return -1, -1
+	}
+	for start = from; start < len(query); start = end + 1 {
+		end = len(query)
+		if i := strings.IndexAny(query[start:], seps); i >= 0 {
+			end = start + i
+		}
+		rawKey, _, _ := strings.Cut(query[start:end], "=")
+		if rawKey != "" && unescapedEqual(rawKey, key, plusAsSpace) {
+			return start, end
+		}
+	}
+	return -1, -1
+}
 
-	if i := strings.IndexAny(s, seps); i >= 0 {
-		return s[:i], s[i+1:]
+// unescapedEqual reports whether the escaped string s is equal to t after unescaping,
+// without allocating a decoded copy of s. If plusAsSpace is true, '+' in s is decoded as a space.
+// If s contains an invalid escape sequence, s is compared with t as is.
+func unescapedEqual(s, t string, plusAsSpace bool) bool {
+	if strings.IndexByte(s, '%') < 0 && (!plusAsSpace || strings.IndexByte(s, '+') < 0) {
+		return s == t
+	}
+	j := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '+' && plusAsSpace:
+			c = ' '
+		case c == '%':
+			if i+2 >= len(s) || !ishex(s[i+1]) || !ishex(s[i+2]) {
+				return s == t
+			}
+			c = unhex(s[i+1])<<4 | unhex(s[i+2])
+			i += 2
+		}
+		if j >= len(t) || t[j] != c {
+			return false
+		}
+		j++
 	}
-	return s, ""
+	return j == len(t)
 }
 
-func trimParamSeparator(s string) (string, string) {
+func ishex(c byte) bool {
+	switch {
+	case '0' <= c && c <= '9':
+		return true
+	case 'a' <= c && c <= 'f':
+		return true
+	case 'A' <= c && c <= 'F':
+		return true
+	}
+	return false
+}
 
-	if strings.HasSuffix(s, paramSep) ||
-		strings.HasSuffix(s, deprecatedParamSep) {
-		// length of suffix is always 1
-		return s[:len(s)-1], s[len(s)-1:]
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10
 	}
+	return 0
+}
+
+// paramValue returns the raw value of the param segment.
+// It returns an empty string for a valueless param.
+func paramValue(param string) string {
+	_, value, _ := strings.Cut(param, "=")
+	return value
+}
+
+// isFlag reports whether the param segment is a bare key without '='.
+func isFlag(param string) bool {
+	return strings.IndexByte(param, '=') < 0
+}
 
-	return s, ""
+func isSeparator(c byte, seps string) bool {
+	return strings.IndexByte(seps, c) >= 0
+}
+
+// queryCutter rebuilds a query string while params are removed from it or replaced.
+// Every removed param takes its trailing separators along, and the preceding ones but the first,
+// so the empty segments around it collapse. If it is the last param in the query, it takes all the preceding ones.
+// Params must be passed in the order they appear in the query.
+type queryCutter struct {
+	query string
+	seps  string
+	buf   strings.Builder
+	last  int
+	trim  bool
+}
+
+// remove drops the param bounded by start and end.
+func (c *queryCutter) remove(start, end int) {
+	c.grow()
+	from := start
+	for from > c.last && isSeparator(c.query[from-1], c.seps) {
+		from--
+	}
+	if from > 0 && from < start {
+		from++
+	}
+	for end < len(c.query) && isSeparator(c.query[end], c.seps) {
+		end++
+	}
+	c.buf.WriteString(c.query[c.last:from])
+	c.last = end
+	c.trim = end == len(c.query)
+}
+
+// replace puts s in place of the param bounded by start and end.
+func (c *queryCutter) replace(start, end int, s string) {
+	c.grow()
+	c.buf.WriteString(c.query[c.last:start])
+	c.buf.WriteString(s)
+	c.last = end
+	c.trim = false
+}
+
+func (c *queryCutter) grow() {
+	if c.buf.Cap() == 0 {
+		c.buf.Grow(len(c.query))
+	}
+}
+
+// String returns the resulting query string.
+func (c *queryCutter) String() string {
+	if c.last < len(c.query) {
+		c.buf.WriteString(c.query[c.last:])
+		c.last = len(c.query)
+	}
+	result := c.buf.String()
+	if c.trim {
+		result = strings.TrimRight(result, c.seps)
+	}
+	return result
 }
 
-func errorMerge(err error, err1 error) error {
-	if err == nil {
-		return err1
-	} else if err1 == nil {
-		return err
-	} else {
-		return fmt.Errorf("%w; %w", err, err1)
+// paramIndex returns the number of params in query, empty segments are not counted.
+func paramIndex(query, seps string) int {
+	n := 0
+	for query != "" {
+		i := strings.IndexAny(query, seps)
+		if i < 0 {
+			return n + 1
+		}
+		if i > 0 {
+			n++
+		}
+		query = query[i+1:]
 	}
+	return n
 }
@@ -0,0 +1,228 @@
+package urlqm
+
+// IndexedParams is an ordered list of params, like [Params], which also keeps an index of param positions by key,
+// so looking a param up by its key doesn't scan the whole list.
+// It is worth using for long lists with hundreds of params, which are looked up many times.
+// Adding a param is cheap, while removing one rebuilds the index.
+//
+// The zero value is an empty list ready to use. IndexedParams must not be copied after first use.
+type IndexedParams struct {
+	params Params
+	index  map[string][]int
+}
+
+// NewIndexedParams returns an [IndexedParams] built on top of the given params.
+// The params slice is used as is and must not be modified afterwards by other means.
+func NewIndexedParams(params []Param) *IndexedParams {
+	p := &IndexedParams{params: params}
+	p.reindex()
+	return p
+}
+
+// ParseIndexedQuery takes a query string and returns an [IndexedParams]. See [ParseParams].
+func ParseIndexedQuery(rawQuery string) (*IndexedParams, error) {
+	params, err := ParseParams(rawQuery)
+	return NewIndexedParams(params), err
+}
+
+// Params returns the underlying params in their order.
+// The returned slice is shared with p and must not be modified.
+func (p *IndexedParams) Params() Params {
+	return p.params
+}
+
+// Len returns the number of params.
+func (p *IndexedParams) Len() int {
+	return len(p.params)
+}
+
+// Encode transforms the params into an url-encoded string and returns it. Same as [Params.Encode].
+func (p *IndexedParams) Encode() string {
+	return EncodeParams(p.params)
+}
+
+// EncodeWith is like [IndexedParams.Encode], but it escapes keys and values with the given [Escaper].
+func (p *IndexedParams) EncodeWith(e Escaper) string {
+	return EncodeParamsWith(p.params, e)
+}
+
+// Sort sorts the params by key in ascending order. Same as [Params.Sort].
+func (p *IndexedParams) Sort() {
+	p.params.Sort()
+	p.reindex()
+}
+
+// SetOrder sets the order for the params. Same as [Params.SetOrder].
+func (p *IndexedParams) SetOrder(order ...string) {
+	p.params.SetOrder(order...)
+	p.reindex()
+}
+
+// Add adds a new param to the end of the list. Same as [Params.Add].
+func (p *IndexedParams) Add(key string, values ...string) {
+	if key == "" {
+		return
+	}
+	if len(values) == 0 {
+		p.append(Param{Key: key})
+		return
+	}
+	for _, value := range values {
+		p.append(Param{Key: key, Value: value})
+	}
+}
+
+// Set sets a param with given key and value. Same as [Params.Set].
+func (p *IndexedParams) Set(key, value string) {
+	p.set(Param{Key: key, Value: value})
+}
+
+// SetFlag sets a valueless param (a bare key) with given key. Same as [Params.SetFlag].
+func (p *IndexedParams) SetFlag(key string) {
+	p.set(Param{Key: key, Flag: true})
+}
+
+// HasFlag returns true if the list contains a valueless param with given key.
+func (p *IndexedParams) HasFlag(key string) bool {
+	for _, i := range p.index[key] {
+		if p.params[i].Flag {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the first value of a param with given key or an empty string if not found.
+func (p *IndexedParams) Get(key string) string {
+	value, _ := p.Lookup(key)
+	return value
+}
+
+// Lookup returns the first value of a param with given key and reports whether the param is present.
+func (p *IndexedParams) Lookup(key string) (string, bool) {
+	positions := p.index[key]
+	if len(positions) == 0 {
+		return "", false
+	}
+	return p.params[positions[0]].Value, true
+}
+
+// GetAll returns all values for the given key, if not found returns nil.
+func (p *IndexedParams) GetAll(key string) []string {
+	positions := p.index[key]
+	if len(positions) == 0 {
+		return nil
+	}
+	values := make([]string, len(positions))
+	for i, pos := range positions {
+		values[i] = p.params[pos].Value
+	}
+	return values
+}
+
+// Extract removes the first param with given key from the list and returns its value.
+func (p *IndexedParams) Extract(key string) (value string) {
+	value, _ = p.LookupExtract(key)
+	return value
+}
+
+// LookupExtract removes the first param with given key from the list and returns its value.
+// It also reports whether the param was present and removed.
+func (p *IndexedParams) LookupExtract(key string) (value string, found bool) {
+	positions := p.index[key]
+	if len(positions) == 0 {
+		return "", false
+	}
+	value = p.params[positions[0]].Value
+	p.removeAt(positions[:1])
+	return value, true
+}
+
+// ExtractAll removes all params with given key from the list and returns their values.
+// The returned slice is nil only if nothing was removed.
+func (p *IndexedParams) ExtractAll(key string) []string {
+	values := p.GetAll(key)
+	if values != nil {
+		p.removeAt(p.index[key])
+	}
+	return values
+}
+
+// Delete removes the first param with given key from the list.
+func (p *IndexedParams) Delete(key string) {
+	if positions := p.index[key]; len(positions) > 0 {
+		p.removeAt(positions[:1])
+	}
+}
+
+// DeleteAll removes all params with given key from the list.
+func (p *IndexedParams) DeleteAll(key string) {
+	if positions := p.index[key]; len(positions) > 0 {
+		p.removeAt(positions)
+	}
+}
+
+// Has returns true if the list contains a param with given key.
+func (p *IndexedParams) Has(key string) bool {
+	return len(p.index[key]) > 0
+}
+
+func (p *IndexedParams) append(param Param) {
+	if p.index == nil {
+		p.index = make(map[string][]int)
+	}
+	p.index[param.Key] = append(p.index[param.Key], len(p.params))
+	p.params = append(p.params, param)
+}
+
+func (p *IndexedParams) set(param Param) {
+	if param.Key == "" {
+		return
+	}
+	positions := p.index[param.Key]
+	if len(positions) == 0 {
+		p.append(param)
+		return
+	}
+	first := positions[0]
+	// keep the original form of the replaced param, so its separator and key stay untouched.
+	param.raw = p.params[first].raw
+	p.params[first] = param
+	if len(positions) > 1 {
+		p.removeAt(positions[1:])
+	}
+}
+
+// removeAt removes the params at the given ascending positions and rebuilds the index.
+func (p *IndexedParams) removeAt(positions []int) {
+	j := positions[0]
+	for i, k := j, 0; i < len(p.params); i++ {
+		if k < len(positions) && positions[k] == i {
+			k++
+			continue
+		}
+		p.params[j] = p.params[i]
+		j++
+	}
+	clearParams(p.params[j:])
+	p.params = p.params[:j]
+	p.reindex()
+}
+
+// reindex rebuilds the index from scratch, reusing its allocated position slices.
+func (p *IndexedParams) reindex() {
+	if p.index == nil {
+		p.index = make(map[string][]int, len(p.params))
+	}
+	for key, positions := range p.index {
+		p.index[key] = positions[:0]
+	}
+	for i, param := range p.params {
+		p.index[param.Key] = append(p.index[param.Key], i)
+	}
+	for key, positions := range p.index {
+		if len(positions) == 0 {
+			delete(p.index, key)
+		}
+	}
+}
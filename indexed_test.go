@@ -0,0 +1,189 @@
+package urlqm
+
+import (
+	"reflect"
+	"testing"
+)
+
+// paramList is the method set shared by [Params] and [IndexedParams].
+type paramList interface {
+	Add(key string, values ...string)
+	Set(key, value string)
+	SetFlag(key string)
+	Get(key string) string
+	Lookup(key string) (string, bool)
+	GetAll(key string) []string
+	Has(key string) bool
+	HasFlag(key string) bool
+	Extract(key string) string
+	LookupExtract(key string) (string, bool)
+	ExtractAll(key string) []string
+	Delete(key string)
+	DeleteAll(key string)
+	Sort()
+	SetOrder(order ...string)
+	Encode() string
+}
+
+var (
+	_ paramList = (*Params)(nil)
+	_ paramList = (*IndexedParams)(nil)
+)
+
+// checkIndex verifies that every param is indexed by its key at its position, and nothing else is.
+func checkIndex(t *testing.T, p *IndexedParams) {
+	t.Helper()
+	want := make(map[string][]int)
+	for i, param := range p.params {
+		want[param.Key] = append(want[param.Key], i)
+	}
+	if len(want) == 0 && len(p.index) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(p.index, want) {
+		t.Errorf("IndexedParams index = %v, want %v", p.index, want)
+	}
+}
+
+func TestIndexedParams(t *testing.T) {
+	initial := Params{
+		{Key: "k1", Value: "v1"},
+		{Key: "k2", Value: "v2"},
+		{Key: "k2", Value: "v3"},
+		{Key: "debug", Flag: true},
+		{Key: "k3", Value: "v4"},
+		{Key: "k2", Value: "v5"},
+	}
+	tests := []struct {
+		name string
+		op   func(p paramList) []any
+	}{
+		{
+			name: "Lookups",
+			op: func(p paramList) []any {
+				v, ok := p.Lookup("k2")
+				missing, missingOk := p.Lookup("k4")
+				return []any{
+					p.Get("k2"), p.Get("k4"), v, ok, missing, missingOk,
+					p.GetAll("k2"), p.GetAll("k4"), p.Has("debug"), p.Has("k4"),
+					p.HasFlag("debug"), p.HasFlag("k1"), p.Encode(),
+				}
+			},
+		},
+		{
+			name: "Add",
+			op: func(p paramList) []any {
+				p.Add("k4", "v6", "v7")
+				p.Add("k1")
+				p.Add("")
+				return []any{p.GetAll("k4"), p.GetAll("k1")}
+			},
+		},
+		{
+			name: "Set",
+			op: func(p paramList) []any {
+				p.Set("k2", "new")
+				p.Set("k5", "v8")
+				p.SetFlag("k1")
+				p.Set("", "v9")
+				return []any{p.GetAll("k2"), p.Get("k5"), p.HasFlag("k1")}
+			},
+		},
+		{
+			name: "Extract",
+			op: func(p paramList) []any {
+				first := p.Extract("k2")
+				v, ok := p.LookupExtract("k3")
+				missing, missingOk := p.LookupExtract("k3")
+				return []any{first, v, ok, missing, missingOk, p.GetAll("k2"), p.Get("k3")}
+			},
+		},
+		{
+			name: "ExtractAll",
+			op: func(p paramList) []any {
+				return []any{p.ExtractAll("k2"), p.ExtractAll("k2"), p.Get("k3")}
+			},
+		},
+		{
+			name: "Delete",
+			op: func(p paramList) []any {
+				p.Delete("k1")
+				p.Delete("k2")
+				p.DeleteAll("debug")
+				p.DeleteAll("k4")
+				return []any{p.Get("k1"), p.GetAll("k2"), p.Has("debug")}
+			},
+		},
+		{
+			name: "DeleteAll",
+			op: func(p paramList) []any {
+				p.DeleteAll("k2")
+				p.Add("k2", "v6")
+				return []any{p.GetAll("k2"), p.Get("k3")}
+			},
+		},
+		{
+			name: "Sort",
+			op: func(p paramList) []any {
+				p.Sort()
+				return []any{p.GetAll("k2"), p.Get("k1")}
+			},
+		},
+		{
+			name: "SetOrder",
+			op: func(p paramList) []any {
+				p.SetOrder("k3", "k2")
+				p.Delete("k3")
+				return []any{p.GetAll("k2"), p.Get("k3")}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := append(Params(nil), initial...)
+			want := tt.op(&params)
+
+			indexed := NewIndexedParams(append(Params(nil), initial...))
+			got := tt.op(indexed)
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("IndexedParams results = %v, want %v", got, want)
+			}
+			if !reflect.DeepEqual(indexed.Params(), params) {
+				t.Errorf("IndexedParams.Params() = %v, want %v", indexed.Params(), params)
+			}
+			if indexed.Len() != len(params) {
+				t.Errorf("IndexedParams.Len() = %d, want %d", indexed.Len(), len(params))
+			}
+			checkIndex(t, indexed)
+		})
+	}
+}
+
+func TestIndexedParams_ZeroValue(t *testing.T) {
+	var p IndexedParams
+	if p.Has("a") || p.Get("a") != "" || p.GetAll("a") != nil {
+		t.Fatalf("zero IndexedParams is not empty")
+	}
+	p.Delete("a")
+	p.Set("a", "1")
+	p.Add("b", "2")
+	if got, want := p.Encode(), "a=1&b=2"; got != want {
+		t.Errorf("IndexedParams.Encode() = %q, want %q", got, want)
+	}
+	checkIndex(t, &p)
+}
+
+func TestParseIndexedQuery(t *testing.T) {
+	p, err := ParseIndexedQuery("a=1&b=2&brightness=90%&a=3")
+	if err == nil {
+		t.Errorf("ParseIndexedQuery() error = nil, want an error")
+	}
+	if got, want := p.GetAll("a"), []string{"1", "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("IndexedParams.GetAll() = %v, want %v", got, want)
+	}
+	if got, want := p.Get("brightness"), "90%"; got != want {
+		t.Errorf("IndexedParams.Get() = %q, want %q", got, want)
+	}
+	checkIndex(t, p)
+}
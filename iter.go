@@ -0,0 +1,101 @@
+//go:build go1.23
+
+package urlqm
+
+import "iter"
+
+// QueryParams returns an iterator over the decoded keys and values of the params in a raw query string.
+// It splits the query with the default [Parser] rules, see [Parser.QueryParams].
+//
+//	for key, value := range urlqm.QueryParams(u.RawQuery) {
+//		fmt.Println(key, value)
+//	}
+func QueryParams(rawQuery string) iter.Seq2[string, string] {
+	return defaultParser.QueryParams(rawQuery)
+}
+
+// QueryParams returns an iterator over the decoded keys and values of the params in a raw query string,
+// in the order they appear. No []Param is built, the query is walked with a [QueryScanner].
+// Keys and values that fail to be decoded are yielded as they are, like [Parser.ParseParams] keeps them;
+// a strict parser stops at the first malformed param instead. Use [QueryScanner] if the errors matter.
+func (p Parser) QueryParams(rawQuery string) iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		s := QueryScanner{query: rawQuery, parser: p, index: -1}
+		for s.Next() {
+			key, kerr := s.decodeKey()
+			value, verr := s.decodeValue()
+			if p.Strict && (kerr != nil || verr != nil) {
+				return
+			}
+			if !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the keys and values of the params, in their order.
+func (p Params) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for _, param := range p {
+			if !yield(param.Key, param.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns an iterator over the unique keys of the params, in the order they first appear.
+func (p Params) Keys() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		seen := make(map[string]struct{}, len(p))
+		for _, param := range p {
+			if _, ok := seen[param.Key]; ok {
+				continue
+			}
+			seen[param.Key] = struct{}{}
+			if !yield(param.Key) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over all values for the given key, in their order.
+// It is a lazy counterpart of [Params.GetAll].
+func (p Params) Values(key string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for _, param := range p {
+			if param.Key == key && !yield(param.Value) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the keys and values of the params, in their order. Same as [Params.All].
+func (p *IndexedParams) All() iter.Seq2[string, string] {
+	return p.params.All()
+}
+
+// Keys returns an iterator over the unique keys of the params, in the order they first appear.
+func (p *IndexedParams) Keys() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for i, param := range p.params {
+			if p.index[param.Key][0] == i && !yield(param.Key) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over all values for the given key, in their order.
+func (p *IndexedParams) Values(key string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for _, i := range p.index[key] {
+			if !yield(p.params[i].Value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+//go:build go1.23
+
+package urlqm
+
+import (
+	"iter"
+	"reflect"
+	"testing"
+)
+
+func TestQueryParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser Parser
+		query  string
+		want   [][2]string
+	}{
+		{
+			name:  "Empty",
+			query: "",
+			want:  nil,
+		},
+		{
+			name:  "Decoded pairs in order",
+			query: "q=a+b&region=1&debug;region=%D0%9A%D0%B8%D1%97%D0%B2&&",
+			want:  [][2]string{{"q", "a b"}, {"region", "1"}, {"debug", ""}, {"region", "Київ"}},
+		},
+		{
+			name:  "Malformed param is yielded as is",
+			query: "a=1&brightness=90%&b=2",
+			want:  [][2]string{{"a", "1"}, {"brightness", "90%"}, {"b", "2"}},
+		},
+		{
+			name:   "Strict parser stops at malformed param",
+			parser: Parser{Strict: true},
+			query:  "a=1&brightness=90%&b=2",
+			want:   [][2]string{{"a", "1"}},
+		},
+		{
+			name:   "Custom separators and plus",
+			parser: Parser{Separators: "&", KeepPlus: true},
+			query:  "path=a;b&expr=1+2",
+			want:   [][2]string{{"path", "a;b"}, {"expr", "1+2"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][2]string
+			for key, value := range tt.parser.QueryParams(tt.query) {
+				got = append(got, [2]string{key, value})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryParams_Break(t *testing.T) {
+	var keys []string
+	for key := range QueryParams("a=1&b=2&c=3") {
+		keys = append(keys, key)
+		if key == "b" {
+			break
+		}
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("QueryParams() keys = %v, want %v", keys, want)
+	}
+}
+
+func TestParams_Iterators(t *testing.T) {
+	params := Params{
+		{Key: "a", Value: "1"},
+		{Key: "b", Value: "2"},
+		{Key: "a", Value: "3"},
+		{Key: "debug", Flag: true},
+		{Key: "b", Value: "4"},
+	}
+
+	for _, p := range []interface {
+		All() iter.Seq2[string, string]
+		Keys() iter.Seq[string]
+		Values(key string) iter.Seq[string]
+	}{&params, NewIndexedParams(params)} {
+		testParamsIterators(t, p)
+	}
+}
+
+func testParamsIterators(t *testing.T, params interface {
+	All() iter.Seq2[string, string]
+	Keys() iter.Seq[string]
+	Values(key string) iter.Seq[string]
+}) {
+	t.Helper()
+	var all [][2]string
+	for key, value := range params.All() {
+		all = append(all, [2]string{key, value})
+	}
+	wantAll := [][2]string{{"a", "1"}, {"b", "2"}, {"a", "3"}, {"debug", ""}, {"b", "4"}}
+	if !reflect.DeepEqual(all, wantAll) {
+		t.Errorf("Params.All() = %v, want %v", all, wantAll)
+	}
+
+	var keys []string
+	for key := range params.Keys() {
+		keys = append(keys, key)
+	}
+	if want := []string{"a", "b", "debug"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("Params.Keys() = %v, want %v", keys, want)
+	}
+
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{key: "a", want: []string{"1", "3"}},
+		{key: "b", want: []string{"2", "4"}},
+		{key: "debug", want: []string{""}},
+		{key: "missing", want: nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for value := range params.Values(tt.key) {
+			got = append(got, value)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Params.Values(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
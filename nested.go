@@ -0,0 +1,544 @@
+package urlqm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default limits of [NestedParser].
+const (
+	DefaultMaxDepth = 5
+	DefaultMaxIndex = 20
+)
+
+// NodeKind is the kind of a [Node].
+type NodeKind uint8
+
+const (
+	// NodeValue is a leaf node with a value.
+	NodeValue NodeKind = iota
+	// NodeObject is a node with keyed children, like `filter` in `filter[status]=open`.
+	NodeObject
+	// NodeArray is a node with ordered items, like `tags` in `tags[]=a&tags[]=b` or `items` in `items[0][id]=5`.
+	NodeArray
+)
+
+// Node is a node of a tree of params in bracket notation, as used by qs and Rack:
+// `filter[status]=open&filter[tags][]=a&items[0][id]=5`.
+type Node struct {
+	Kind NodeKind
+	// Value is the value of a [NodeValue] node.
+	Value string
+	// Keys are the keys of the children of a [NodeObject] node, in the order they first appear.
+	Keys []string
+	// Children are the children of a [NodeObject] node, in the order of Keys,
+	// or the items of a [NodeArray] node.
+	Children []*Node
+
+	// indices are the array indices of the items while the tree is being built,
+	// items maps an array index to its item in Children, and nextIndex is the index of an item appended with `[]`.
+	indices   []int
+	items     map[int]int
+	nextIndex int
+	// keyIndex maps a key of an object node to its child in Children while the tree is being built.
+	keyIndex map[string]int
+}
+
+// Get returns the child of an object node with given key, or nil if there is none.
+func (n *Node) Get(key string) *Node {
+	if n == nil || n.Kind != NodeObject {
+		return nil
+	}
+	for i, k := range n.Keys {
+		if k == key {
+			return n.Children[i]
+		}
+	}
+	return nil
+}
+
+// Index returns the i-th item of an array node, or nil if there is none.
+func (n *Node) Index(i int) *Node {
+	if n == nil || n.Kind != NodeArray || i < 0 || i >= len(n.Children) {
+		return nil
+	}
+	return n.Children[i]
+}
+
+// Find returns the node at the given path of object keys and array indices,
+// like `Find("items", "0", "id")`, or nil if there is none.
+func (n *Node) Find(path ...string) *Node {
+	for _, key := range path {
+		if n == nil {
+			return nil
+		}
+		if n.Kind == NodeArray {
+			i, err := strconv.Atoi(key)
+			if err != nil {
+				return nil
+			}
+			n = n.Index(i)
+			continue
+		}
+		n = n.Get(key)
+	}
+	return n
+}
+
+// Set sets the child of an object node with given key, replacing an existing one.
+func (n *Node) Set(key string, child *Node) {
+	for i, k := range n.Keys {
+		if k == key {
+			n.Children[i] = child
+			return
+		}
+	}
+	n.Keys = append(n.Keys, key)
+	n.Children = append(n.Children, child)
+}
+
+// Append appends items to an array node.
+func (n *Node) Append(items ...*Node) {
+	n.Children = append(n.Children, items...)
+}
+
+// Interface returns the tree as plain Go values: a string for a value node,
+// a []any for an array node and a map[string]any for an object node.
+func (n *Node) Interface() any {
+	switch n.Kind {
+	case NodeObject:
+		m := make(map[string]any, len(n.Keys))
+		for i, key := range n.Keys {
+			m[key] = n.Children[i].Interface()
+		}
+		return m
+	case NodeArray:
+		items := make([]any, len(n.Children))
+		for i, child := range n.Children {
+			items[i] = child.Interface()
+		}
+		return items
+	}
+	return n.Value
+}
+
+// NestedParser decodes params in bracket notation into a tree of [Node] and encodes them back.
+// The zero value is ready to use.
+type NestedParser struct {
+	// MaxDepth limits the number of nested brackets in a key.
+	// Brackets beyond the limit are kept as a literal key, like qs does:
+	// with MaxDepth 1, `a[b][c]=d` decodes to `{"a": {"b": {"[c]": "d"}}}`.
+	// If it is 0, [DefaultMaxDepth] is used.
+	MaxDepth int
+
+	// MaxIndex limits array indices, so `a[999999999]=x` can't make a huge array.
+	// An array with a larger index is turned into an object with the indices as keys.
+	// If it is 0, [DefaultMaxIndex] is used.
+	MaxIndex int
+
+	// Indices makes [NestedParser.Encode] write array items with their indices, like `tags[0]=a`, as qs does by default,
+	// instead of empty brackets, like `tags[]=a`, as Rack does.
+	Indices bool
+}
+
+var defaultNestedParser NestedParser
+
+// ParseNested parses the query string and decodes its params into a tree. See [NestedParser.Parse].
+func ParseNested(rawQuery string) (*Node, error) {
+	return defaultNestedParser.Parse(rawQuery)
+}
+
+// UnmarshalNested parses the query string and stores it into the struct pointed to by v.
+// See [NestedParser.Unmarshal].
+func UnmarshalNested(rawQuery string, v any) error {
+	return defaultNestedParser.Unmarshal(rawQuery, v)
+}
+
+// EncodeNested returns the params of the tree in bracket notation. See [NestedParser.Encode].
+func EncodeNested(root *Node) Params {
+	return defaultNestedParser.Encode(root)
+}
+
+// Parse parses the query string with [ParseParams] and decodes its params into a tree,
+// see [NestedParser.Decode]. Parse errors are returned along with the decoding errors.
+func (np NestedParser) Parse(rawQuery string) (*Node, error) {
+	params, err := ParseParams(rawQuery)
+	root, decodeErr := np.Decode(params)
+	return root, errors.Join(err, decodeErr)
+}
+
+// Decode decodes params in bracket notation into a tree, whose root is an object node.
+//
+// `a[b]=c` makes an object, `a[]=c` appends an item to an array, and `a[0]=c` sets the item of an array at the index.
+// Sparse indices are compacted, so `a[1]=x&a[5]=y` makes an array of two items.
+// Like Rack does, `a[][b]=c` sets the key of the last item of the array, unless the item already has that key,
+// in which case a new item is appended. A repeated key without brackets makes an array of values, like qs does.
+// A param that conflicts with another one, like `a[b]=c` after `a=d`, is skipped and reported with [ErrNestedConflict].
+func (np NestedParser) Decode(params Params) (*Node, error) {
+	root := &Node{Kind: NodeObject}
+	var errs []error
+	for _, param := range params {
+		path := splitNestedKey(param.Key, np.maxDepth())
+		if !np.insert(root, path, param.Value) {
+			errs = append(errs, fmt.Errorf("urlqm: parameter %q: %w", param.Key, ErrNestedConflict))
+		}
+	}
+	finishArrays(root)
+	return root, errors.Join(errs...)
+}
+
+// Encode returns the params of the tree in bracket notation, the counterpart of [NestedParser.Decode].
+// The root must be an object node. Empty objects and arrays produce no params.
+func (np NestedParser) Encode(root *Node) Params {
+	if root == nil || root.Kind != NodeObject {
+		return nil
+	}
+	var params Params
+	for i, key := range root.Keys {
+		params = np.appendNode(params, key, root.Children[i])
+	}
+	return params
+}
+
+// Unmarshal parses the query string in bracket notation and stores it into the struct pointed to by v.
+// See [Node.Decode].
+func (np NestedParser) Unmarshal(rawQuery string, v any) error {
+	root, err := np.Parse(rawQuery)
+	return errors.Join(err, root.Decode(v))
+}
+
+func (np NestedParser) maxDepth() int {
+	if np.MaxDepth <= 0 {
+		return DefaultMaxDepth
+	}
+	return np.MaxDepth
+}
+
+func (np NestedParser) maxIndex() int {
+	if np.MaxIndex <= 0 {
+		return DefaultMaxIndex
+	}
+	return np.MaxIndex
+}
+
+// splitNestedKey splits a key in bracket notation into its path: `a[b][]` becomes ["a", "b", ""].
+// Brackets beyond maxDepth, and everything after malformed brackets, make the last segment as is.
+func splitNestedKey(key string, maxDepth int) []string {
+	open := strings.IndexByte(key, '[')
+	if open <= 0 {
+		return []string{key}
+	}
+	path := []string{key[:open]}
+	rest := key[open:]
+	for rest != "" {
+		if len(path) > maxDepth || rest[0] != '[' {
+			break
+		}
+		end := strings.IndexAny(rest[1:], "[]")
+		if end < 0 || rest[1+end] != ']' {
+			break
+		}
+		path = append(path, rest[1:1+end])
+		rest = rest[end+2:]
+	}
+	if rest != "" {
+		path = append(path, rest)
+	}
+	return path
+}
+
+// insert puts the value into the tree of the object or array node n at the given path.
+// It reports false on a conflict.
+func (np NestedParser) insert(n *Node, path []string, value string) bool {
+	key := path[0]
+	last := len(path) == 1
+
+	var child *Node
+	if n.Kind == NodeArray {
+		index, err := strconv.Atoi(key)
+		switch {
+		case key == "":
+			if item := lastArrayItem(n); !last && path[1] != "" && item != nil &&
+				item.Kind == NodeObject && objectChild(item, path[1]) == nil {
+				// like Rack, `a[][b]=1&a[][c]=2` sets both keys of a single item.
+				return np.insert(item, path[1:], value)
+			}
+			index = nextArrayIndex(n)
+		case err != nil || index < 0 || index > np.maxIndex():
+			arrayToObject(n)
+			return np.insert(n, path, value)
+		default:
+			child = arrayItem(n, index)
+		}
+		if child == nil {
+			child = newNestedChild(path, value)
+			addArrayItem(n, index, child)
+			return last || np.insert(child, path[1:], value)
+		}
+	} else if child = objectChild(n, key); child == nil {
+		child = newNestedChild(path, value)
+		addObjectChild(n, key, child)
+		return last || np.insert(child, path[1:], value)
+	}
+
+	switch {
+	case last && child.Kind == NodeValue:
+		// like qs, a repeated key turns the value into an array of values.
+		first := child.Value
+		*child = Node{Kind: NodeArray}
+		addArrayItem(child, 0, &Node{Value: first})
+		addArrayItem(child, 1, &Node{Value: value})
+		return true
+	case last && child.Kind == NodeArray:
+		addArrayItem(child, nextArrayIndex(child), &Node{Value: value})
+		return true
+	case last || child.Kind == NodeValue:
+		return false
+	}
+	return np.insert(child, path[1:], value)
+}
+
+// newNestedChild returns a new node for path[0]: a value node if it is the last one,
+// otherwise an empty array or object node, depending on the next segment of the path.
+func newNestedChild(path []string, value string) *Node {
+	if len(path) == 1 {
+		return &Node{Value: value}
+	}
+	next := path[1]
+	if next == "" {
+		return &Node{Kind: NodeArray}
+	}
+	if _, err := strconv.Atoi(next); err == nil && next[0] != '-' && next[0] != '+' {
+		return &Node{Kind: NodeArray}
+	}
+	return &Node{Kind: NodeObject}
+}
+
+// objectChild is [Node.Get] for an object node that is being built, it takes constant time.
+func objectChild(n *Node, key string) *Node {
+	if i, ok := n.keyIndex[key]; ok {
+		return n.Children[i]
+	}
+	return nil
+}
+
+func addObjectChild(n *Node, key string, child *Node) {
+	if n.keyIndex == nil {
+		n.keyIndex = make(map[string]int)
+	}
+	n.keyIndex[key] = len(n.Children)
+	n.Keys = append(n.Keys, key)
+	n.Children = append(n.Children, child)
+}
+
+func arrayItem(n *Node, index int) *Node {
+	if i, ok := n.items[index]; ok {
+		return n.Children[i]
+	}
+	return nil
+}
+
+func addArrayItem(n *Node, index int, item *Node) {
+	if n.items == nil {
+		n.items = make(map[int]int)
+	}
+	n.items[index] = len(n.Children)
+	n.Children = append(n.Children, item)
+	n.indices = append(n.indices, index)
+	if index >= n.nextIndex {
+		n.nextIndex = index + 1
+	}
+}
+
+func lastArrayItem(n *Node) *Node {
+	if len(n.Children) == 0 {
+		return nil
+	}
+	return n.Children[len(n.Children)-1]
+}
+
+func nextArrayIndex(n *Node) int {
+	return n.nextIndex
+}
+
+// arrayToObject turns an array node into an object node with the item indices as keys.
+func arrayToObject(n *Node) {
+	n.Kind = NodeObject
+	n.Keys = make([]string, len(n.indices))
+	n.keyIndex = make(map[string]int, len(n.indices))
+	for i, idx := range n.indices {
+		n.Keys[i] = strconv.Itoa(idx)
+		n.keyIndex[n.Keys[i]] = i
+	}
+	n.indices, n.items, n.nextIndex = nil, nil, 0
+}
+
+// finishArrays orders the array items by their indices and drops the state of building throughout the tree.
+func finishArrays(n *Node) {
+	if n.Kind == NodeArray && n.indices != nil {
+		sort.Stable(byArrayIndex{n})
+	}
+	n.indices, n.items, n.nextIndex, n.keyIndex = nil, nil, 0, nil
+	for _, child := range n.Children {
+		finishArrays(child)
+	}
+}
+
+type byArrayIndex struct{ n *Node }
+
+func (a byArrayIndex) Len() int           { return len(a.n.Children) }
+func (a byArrayIndex) Less(i, j int) bool { return a.n.indices[i] < a.n.indices[j] }
+func (a byArrayIndex) Swap(i, j int) {
+	a.n.Children[i], a.n.Children[j] = a.n.Children[j], a.n.Children[i]
+	a.n.indices[i], a.n.indices[j] = a.n.indices[j], a.n.indices[i]
+}
+
+func (np NestedParser) appendNode(params Params, key string, n *Node) Params {
+	switch n.Kind {
+	case NodeObject:
+		for i, childKey := range n.Keys {
+			// a key starting with a bracket is the rest of a key beyond MaxDepth, see splitNestedKey.
+			if !strings.HasPrefix(childKey, "[") {
+				childKey = "[" + childKey + "]"
+			}
+			params = np.appendNode(params, key+childKey, n.Children[i])
+		}
+	case NodeArray:
+		for i, child := range n.Children {
+			itemKey := key + "[]"
+			if np.Indices {
+				itemKey = key + "[" + strconv.Itoa(i) + "]"
+			}
+			params = np.appendNode(params, itemKey, child)
+		}
+	default:
+		params = append(params, Param{Key: key, Value: n.Value})
+	}
+	return params
+}
+
+// Decode stores the tree into the struct pointed to by v.
+//
+// Struct fields are mapped to object keys by their `query` tags, like [Params.Decode] does.
+// Besides the types supported by [Params.Decode], a field may be a struct, which receives an object node,
+// a map with string keys, which receives the children of an object node,
+// or a slice of any of them, which receives the items of an array node.
+// Failed fields are reported as [DecodeErrors], with the path to the field and the key in bracket notation.
+func (n *Node) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrInvalidTarget
+	}
+	var errs DecodeErrors
+	if n != nil {
+		decodeNode(rv.Elem(), n, "", "", "", &errs)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func decodeNode(v reflect.Value, n *Node, field, key, layout string, errs *DecodeErrors) {
+	fail := func(err error) {
+		*errs = append(*errs, &DecodeError{Field: field, Key: key, Value: n.Value, Err: err})
+	}
+
+	t := v.Type()
+	if t.Kind() == reflect.Pointer && !isTextUnmarshaler(t) {
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		decodeNode(v.Elem(), n, field, key, layout, errs)
+		return
+	}
+
+	if t == durationType || t == timeType || isTextUnmarshaler(t) || isScalarKind(t.Kind()) {
+		if n.Kind == NodeArray && len(n.Children) > 0 {
+			n = n.Children[0]
+		}
+		if n.Kind != NodeValue {
+			fail(fmt.Errorf("%w: cannot decode a nested object into %v", ErrNestedConflict, t))
+			return
+		}
+		if err := decodeValue(v, Param{Key: key, Value: n.Value}, layout); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != NodeObject {
+			fail(fmt.Errorf("%w: cannot decode a value into %v", ErrNestedConflict, t))
+			return
+		}
+		for _, f := range cachedFields(t) {
+			child := n.Get(f.key)
+			if child == nil {
+				continue
+			}
+			name := f.name
+			if field != "" {
+				name = field + "." + f.name
+			}
+			childKey := f.key
+			if key != "" {
+				childKey = key + "[" + f.key + "]"
+			}
+			fv, err := fieldByIndexAlloc(v, f.index)
+			if err != nil {
+				*errs = append(*errs, &DecodeError{Field: name, Key: childKey, Err: err})
+				continue
+			}
+			decodeNode(fv, child, name, childKey, f.layout, errs)
+		}
+	case reflect.Slice:
+		items := n.Children
+		if n.Kind == NodeValue {
+			items = []*Node{n}
+		} else if n.Kind != NodeArray {
+			fail(fmt.Errorf("%w: cannot decode an object into %v", ErrNestedConflict, t))
+			return
+		}
+		s := reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			index := strconv.Itoa(i)
+			decodeNode(s.Index(i), item, field+"["+index+"]", key+"["+index+"]", layout, errs)
+		}
+		v.Set(s)
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			fail(fmt.Errorf("%w: %v", ErrUnsupportedType, t))
+			return
+		}
+		if n.Kind != NodeObject {
+			fail(fmt.Errorf("%w: cannot decode a value into %v", ErrNestedConflict, t))
+			return
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(t, len(n.Keys)))
+		}
+		for i, k := range n.Keys {
+			elem := reflect.New(t.Elem()).Elem()
+			decodeNode(elem, n.Children[i], field+"["+k+"]", key+"["+k+"]", layout, errs)
+			v.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), elem)
+		}
+	default:
+		fail(fmt.Errorf("%w: %v", ErrUnsupportedType, t))
+	}
+}
+
+func isScalarKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
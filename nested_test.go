@@ -0,0 +1,295 @@
+package urlqm
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSplitNestedKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		maxDepth int
+		want     []string
+	}{
+		{key: "a", maxDepth: 5, want: []string{"a"}},
+		{key: "a[b]", maxDepth: 5, want: []string{"a", "b"}},
+		{key: "a[b][]", maxDepth: 5, want: []string{"a", "b", ""}},
+		{key: "items[0][id]", maxDepth: 5, want: []string{"items", "0", "id"}},
+		{key: "a[b][c][d]", maxDepth: 2, want: []string{"a", "b", "c", "[d]"}},
+		{key: "a[b][c]", maxDepth: 1, want: []string{"a", "b", "[c]"}},
+		{key: "[a]", maxDepth: 5, want: []string{"[a]"}},
+		{key: "a[b", maxDepth: 5, want: []string{"a", "[b"}},
+		{key: "a[b]c", maxDepth: 5, want: []string{"a", "b", "c"}},
+		{key: "a[b[c]]", maxDepth: 5, want: []string{"a", "[b[c]]"}},
+	}
+	for _, tt := range tests {
+		if got := splitNestedKey(tt.key, tt.maxDepth); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitNestedKey(%q, %d) = %q, want %q", tt.key, tt.maxDepth, got, tt.want)
+		}
+	}
+}
+
+func TestParseNested(t *testing.T) {
+	tests := []struct {
+		name     string
+		parser   NestedParser
+		query    string
+		want     any
+		wantErrs int
+	}{
+		{
+			name:  "Empty",
+			query: "",
+			want:  map[string]any{},
+		},
+		{
+			name:  "Objects and arrays",
+			query: "filter[status]=open&filter[tags][]=a&filter[tags][]=b&items[0][id]=5&items[1][id]=6&items[0][name]=x&q=1",
+			want: map[string]any{
+				"filter": map[string]any{"status": "open", "tags": []any{"a", "b"}},
+				"items":  []any{map[string]any{"id": "5", "name": "x"}, map[string]any{"id": "6"}},
+				"q":      "1",
+			},
+		},
+		{
+			name:  "Escaped brackets",
+			query: "filter%5Bstatus%5D=open+now",
+			want:  map[string]any{"filter": map[string]any{"status": "open now"}},
+		},
+		{
+			name:  "Sparse indices are compacted in order",
+			query: "a[5]=z&a[1]=x&a[]=y",
+			want:  map[string]any{"a": []any{"x", "z", "y"}},
+		},
+		{
+			name:  "Repeated keys make arrays",
+			query: "a=1&a=2&b[c]=3&b[c]=4&d[]=5&d=6",
+			want: map[string]any{
+				"a": []any{"1", "2"},
+				"b": map[string]any{"c": []any{"3", "4"}},
+				"d": []any{"5", "6"},
+			},
+		},
+		{
+			name:  "Rack style arrays of objects",
+			query: "users[][id]=1&users[][name]=a&users[][id]=2&users[][name]=b",
+			want: map[string]any{
+				"users": []any{map[string]any{"id": "1", "name": "a"}, map[string]any{"id": "2", "name": "b"}},
+			},
+		},
+		{
+			name:   "Index over the limit makes an object",
+			parser: NestedParser{MaxIndex: 3},
+			query:  "a[0]=x&a[100]=y",
+			want:   map[string]any{"a": map[string]any{"0": "x", "100": "y"}},
+		},
+		{
+			name:  "Non-numeric key in an array makes an object",
+			query: "a[0]=x&a[b]=y",
+			want:  map[string]any{"a": map[string]any{"0": "x", "b": "y"}},
+		},
+		{
+			name:   "Depth over the limit is kept literally",
+			parser: NestedParser{MaxDepth: 1},
+			query:  "a[b][c][d]=1",
+			want:   map[string]any{"a": map[string]any{"b": map[string]any{"[c][d]": "1"}}},
+		},
+		{
+			name:     "Conflicts are skipped",
+			query:    "a=1&a[b]=2&c[d]=3&c=4&e[f]=5",
+			want:     map[string]any{"a": "1", "c": map[string]any{"d": "3"}, "e": map[string]any{"f": "5"}},
+			wantErrs: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := tt.parser.Parse(tt.query)
+			if got := root.Interface(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NestedParser.Parse() = %v, want %v", got, tt.want)
+			}
+			var gotErrs int
+			if err != nil {
+				gotErrs = strings.Count(err.Error(), ErrNestedConflict.Error())
+				if !errors.Is(err, ErrNestedConflict) {
+					t.Errorf("NestedParser.Parse() error = %v, want ErrNestedConflict", err)
+				}
+			}
+			if gotErrs != tt.wantErrs {
+				t.Errorf("NestedParser.Parse() errors = %d (%v), want %d", gotErrs, err, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestEncodeNested(t *testing.T) {
+	query := "filter[status]=open&filter[tags][]=a&filter[tags][]=b&items[][id]=5&items[][name]=x&items[][id]=6&q=1"
+	tests := []struct {
+		name   string
+		parser NestedParser
+		want   string
+	}{
+		{
+			name: "Brackets",
+			want: "filter%5Bstatus%5D=open&filter%5Btags%5D%5B%5D=a&filter%5Btags%5D%5B%5D=b" +
+				"&items%5B%5D%5Bid%5D=5&items%5B%5D%5Bname%5D=x&items%5B%5D%5Bid%5D=6&q=1",
+		},
+		{
+			name:   "Indices",
+			parser: NestedParser{Indices: true},
+			want: "filter%5Bstatus%5D=open&filter%5Btags%5D%5B0%5D=a&filter%5Btags%5D%5B1%5D=b" +
+				"&items%5B0%5D%5Bid%5D=5&items%5B0%5D%5Bname%5D=x&items%5B1%5D%5Bid%5D=6&q=1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := tt.parser.Parse(query)
+			if err != nil {
+				t.Fatalf("NestedParser.Parse() error = %v", err)
+			}
+			params := tt.parser.Encode(root)
+			if got := params.Encode(); got != tt.want {
+				t.Errorf("NestedParser.Encode() = %q, want %q", got, tt.want)
+			}
+
+			// the encoded params must decode to the same tree.
+			again, err := tt.parser.Decode(params)
+			if err != nil {
+				t.Fatalf("NestedParser.Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(again, root) {
+				t.Errorf("NestedParser.Decode(Encode()) = %v, want %v", again.Interface(), root.Interface())
+			}
+		})
+	}
+
+	if got := EncodeNested(&Node{Kind: NodeArray}); got != nil {
+		t.Errorf("EncodeNested() of an array root = %v, want nil", got)
+	}
+
+	// the rest of a key beyond MaxDepth is encoded as is.
+	np := NestedParser{MaxDepth: 1}
+	root, err := np.Decode(Params{{Key: "a[b][c]", Value: "d"}, {Key: "e[f][g][h]", Value: "i"}})
+	if err != nil {
+		t.Fatalf("NestedParser.Decode() error = %v", err)
+	}
+	params := np.Encode(root)
+	if want := (Params{{Key: "a[b][c]", Value: "d"}, {Key: "e[f][g][h]", Value: "i"}}); !reflect.DeepEqual(params, want) {
+		t.Errorf("NestedParser.Encode() with MaxDepth = %v, want %v", params, want)
+	}
+	again, err := np.Decode(params)
+	if err != nil {
+		t.Fatalf("NestedParser.Decode() error = %v", err)
+	}
+	if !reflect.DeepEqual(again, root) {
+		t.Errorf("NestedParser.Decode(Encode()) with MaxDepth = %v, want %v", again.Interface(), root.Interface())
+	}
+}
+
+func TestNode_Build(t *testing.T) {
+	tags := &Node{Kind: NodeArray}
+	tags.Append(&Node{Value: "a"}, &Node{Value: "b"})
+	filter := &Node{Kind: NodeObject}
+	filter.Set("status", &Node{Value: "open"})
+	filter.Set("tags", tags)
+	filter.Set("status", &Node{Value: "closed"})
+	root := &Node{Kind: NodeObject}
+	root.Set("filter", filter)
+
+	params := EncodeNested(root)
+	if got, want := params.EncodeWith(SafeSetEscaper("[]")), "filter[status]=closed&filter[tags][]=a&filter[tags][]=b"; got != want {
+		t.Errorf("EncodeNested() = %q, want %q", got, want)
+	}
+	if got := root.Find("filter", "tags", "1"); got == nil || got.Value != "b" {
+		t.Errorf("Node.Find() = %v, want b", got)
+	}
+	for _, path := range [][]string{{"filter", "tags", "x"}, {"filter", "tags", "2"}, {"filter", "status", "x"}, {"missing"}} {
+		if got := root.Find(path...); got != nil {
+			t.Errorf("Node.Find(%q) = %v, want nil", path, got)
+		}
+	}
+}
+
+func TestNestedParser_DecodeMany(t *testing.T) {
+	const n = 20000
+	params := make(Params, 0, 3*n+2)
+	for i := 0; i < n; i++ {
+		params = append(params, Param{Key: "k" + strconv.Itoa(i) + "[x]", Value: strconv.Itoa(i)},
+			Param{Key: "a[]", Value: strconv.Itoa(i)}, Param{Key: "r", Value: strconv.Itoa(i)})
+	}
+	params = append(params, Param{Key: "a[5]", Value: "replaced"}, Param{Key: "a[5]", Value: "more"})
+
+	root, err := NestedParser{}.Decode(params)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got := len(root.Keys); got != n+2 {
+		t.Errorf("len(Keys) = %d, want %d", got, n+2)
+	}
+	if got := root.Find("k19999", "x"); got == nil || got.Value != "19999" {
+		t.Errorf("Find(k19999, x) = %v, want 19999", got)
+	}
+	if got := len(root.Get("a").Children); got != n {
+		t.Errorf("len(a) = %d, want %d", got, n)
+	}
+	if got := root.Find("a", "5"); got == nil || got.Kind != NodeArray || len(got.Children) != 3 {
+		t.Errorf("Find(a, 5) = %v, want an array of 3 values", got)
+	}
+	if got := root.Get("r"); len(got.Children) != n || got.Index(n-1).Value != "19999" {
+		t.Errorf("len(r) = %d, want %d", len(got.Children), n)
+	}
+}
+
+func TestUnmarshalNested(t *testing.T) {
+	type item struct {
+		ID   int    `query:"id"`
+		Name string `query:"name"`
+	}
+	type filter struct {
+		Status string   `query:"status"`
+		Tags   []string `query:"tags"`
+		Min    *float64 `query:"min"`
+	}
+	type target struct {
+		Filter filter            `query:"filter"`
+		Items  []item            `query:"items"`
+		Meta   map[string]string `query:"meta"`
+		Ptr    *item             `query:"ptr"`
+		Page   int               `query:"page"`
+		One    []string          `query:"one"`
+	}
+
+	var got target
+	err := UnmarshalNested("filter[status]=open&filter[tags][]=a&filter[tags][]=b&filter[min]=1.5"+
+		"&items[0][id]=5&items[0][name]=x&items[1][id]=6&meta[a]=1&meta[b]=2&ptr[id]=7&page=2&one=x", &got)
+	if err != nil {
+		t.Fatalf("UnmarshalNested() error = %v", err)
+	}
+	want := target{
+		Filter: filter{Status: "open", Tags: []string{"a", "b"}, Min: ptr(1.5)},
+		Items:  []item{{ID: 5, Name: "x"}, {ID: 6}},
+		Meta:   map[string]string{"a": "1", "b": "2"},
+		Ptr:    &item{ID: 7},
+		Page:   2,
+		One:    []string{"x"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalNested() = %+v, want %+v", got, want)
+	}
+
+	err = UnmarshalNested("filter=open&items[0][id]=x&items[1][id]=6&page[a]=1", &got)
+	var errs DecodeErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("UnmarshalNested() error = %v, want DecodeErrors", err)
+	}
+	var gotErrs [][2]string
+	for _, e := range errs {
+		gotErrs = append(gotErrs, [2]string{e.Field, e.Key})
+	}
+	wantErrs := [][2]string{{"Filter", "filter"}, {"Items[0].ID", "items[0][id]"}, {"Page", "page"}}
+	if !reflect.DeepEqual(gotErrs, wantErrs) {
+		t.Errorf("UnmarshalNested() errors = %v, want %v", gotErrs, wantErrs)
+	}
+}
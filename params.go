@@ -2,31 +2,96 @@
 package urlqm
 
 import (
-	"net/url"
 	"sort"
 	"strings"
 )
 
 // Param represents a key-value pair in a URL query string.
+// Param literals must be keyed, like `Param{Key: "a", Value: "1"}`: Param has unexported fields,
+// and it may gain new fields.
 type Param struct {
 	Key, Value string
+	// Flag marks a valueless param, which appears in the query string as a bare key (`?debug&a=1`).
+	// A flagged param is encoded without '=' and its Value is ignored.
+	Flag bool
+
+	// raw keeps the original form of a param parsed with [Parser.KeepRaw].
+	raw *rawParam
+}
+
+// rawParam is the original form of a param in a query string.
+type rawParam struct {
+	// sep holds the separators that preceded the param in the query string,
+	// lead tells that they are the separators at the beginning of the query string.
+	sep  string
+	lead bool
+	// trail holds the separators at the end of the query string, after the last param.
+	trail string
+	// key and value are still escaped.
+	key, value string
+	// decKey, decValue and flag are the decoded param as it was parsed,
+	// a param is considered modified if they differ from the actual ones.
+	decKey, decValue string
+	flag             bool
+	// styled is the decoded form of a param set in an OpenAPI style, key and value are its form escaped with [FormEscaper].
+	styled *styledForm
 }
 
 // EncodeParams takes a slice of Param and returns the encoded query string.
+// Params parsed with [Parser.KeepRaw] keep their original encoding and separators,
+// unless their key or value was modified.
+// Other params are joined with the latest original separator, or with '&' if there is none.
+// Keys and values are escaped with [FormEscaper], see [EncodeParamsWith] to use another [Escaper].
 func EncodeParams(params []Param) string {
+	return EncodeParamsWith(params, FormEscaper)
+}
+
+// EncodeParamsWith is like [EncodeParams], but it escapes keys and values with the given [Escaper].
+// A nil Escaper means [FormEscaper]. Untouched params parsed with [Parser.KeepRaw] are not re-escaped.
+func EncodeParamsWith(params []Param, e Escaper) string {
 
 	if len(params) == 0 {
 		return ""
 	}
+	e = escaperOrDefault(e)
 	var buf strings.Builder
-
-	for _, param := range params {
-		if buf.Len() > 0 {
-			buf.WriteByte('&')
+	sep := "&"
+
+	for i, param := range params {
+		raw := param.raw
+		switch {
+		case raw != nil && raw.sep != "":
+			if i > 0 || raw.lead {
+				buf.WriteString(raw.sep)
+			}
+			sep = raw.sep[len(raw.sep)-1:]
+		case i > 0:
+			buf.WriteString(sep)
+		}
+		rawKey, rawValue := "", ""
+		if raw != nil {
+			rawKey, rawValue = raw.key, raw.value
+			if raw.styled != nil {
+				rawKey, rawValue = raw.styled.encode(e)
+			}
+		}
+		if raw != nil && param.Key == raw.decKey {
+			buf.WriteString(rawKey)
+		} else {
+			buf.WriteString(e.Escape(param.Key))
+		}
+		if param.Flag {
+			continue
 		}
-		buf.WriteString(url.QueryEscape(param.Key))
 		buf.WriteByte('=')
-		buf.WriteString(url.QueryEscape(param.Value))
+		if raw != nil && !raw.flag && param.Value == raw.decValue {
+			buf.WriteString(rawValue)
+		} else {
+			buf.WriteString(e.Escape(param.Value))
+		}
+	}
+	if raw := params[len(params)-1].raw; raw != nil {
+		buf.WriteString(raw.trail)
 	}
 	return buf.String()
 }
@@ -34,41 +99,11 @@ func EncodeParams(params []Param) string {
 // ParseParams takes a query string and returns a slice of Param.
 // Unlike `url.ParseQuery`, this function collects unescaped keys and values if it fails to unescape them.
 // Also it collects errors from `url.QueryUnescape`. It can be checked with [errors.As] or `err != nil`.
-// If error is not `nil`, it contains all occurred errors.
+// If error is not `nil`, it is a [ParseErrors] list, which contains a [*ParseError] for every failed key or value.
+// A bare key without '=' becomes a Param with Flag set to true.
+// To configure separators, decoding of '+' or strictness, use [Parser.ParseParams].
 func ParseParams(query string) ([]Param, error) {
-	var err error
-
-	if query == "" {
-		return nil, err
-	}
-
-	estLen := strings.Count(query, "&") + strings.Count(query, ";") + 1
-	params := make([]Param, 0, estLen)
-	for query != "" {
-		var key, value string
-		key, query = cutStringByAnySep(query, separators)
-		if key == "" {
-			continue
-		}
-		key, value, _ = strings.Cut(key, "=")
-		// TODO: alloc!
-		if key1, err1 := url.QueryUnescape(key); err1 == nil {
-			key = key1
-		} else {
-			err = errorMerge(err, err1)
-		}
-
-		// TODO: alloc!
-		if value1, err1 := url.QueryUnescape(value); err1 == nil {
-			value = value1
-		} else {
-			err = errorMerge(err, err1)
-		}
-
-		params = append(params, Param{Key: key, Value: value})
-	}
-
-	return params, err
+	return defaultParser.ParseParams(query)
 }
 
 // SortOrderParams sorts the Param slice
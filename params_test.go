@@ -18,22 +18,27 @@ func TestEncodeParams(t *testing.T) {
 	}{
 		{name: "No params", args: args{[]Param{}}, want: ""},
 		{name: "No params nil", args: args{nil}, want: ""},
-		{name: "Simple", args: args{[]Param{{"a", "1"}}}, want: "a=1"},
+		{name: "Simple", args: args{[]Param{{Key: "a", Value: "1"}}}, want: "a=1"},
 		{
 			name: "Unordered multiple values",
-			args: args{[]Param{{"a", "1"}, {"b", "2"}, {"a", "3"}}},
+			args: args{[]Param{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}, {Key: "a", Value: "3"}}},
 			want: "a=1&b=2&a=3",
 		},
 		{
 			name: "Encoded chars",
-			args: args{[]Param{{"q", `"daily news"`}}},
+			args: args{[]Param{{Key: "q", Value: `"daily news"`}}},
 			want: "q=%22daily+news%22",
 		},
 		{
 			name: "Previously bad encoded",
-			args: args{[]Param{{"q", `100%+truth`}}},
+			args: args{[]Param{{Key: "q", Value: `100%+truth`}}},
 			want: "q=100%25%2Btruth",
 		},
+		{
+			name: "Flags",
+			args: args{[]Param{{Key: "debug", Flag: true}, {Key: "a", Value: "1"}, {Key: "b"}, {Key: "v", Value: "x", Flag: true}}},
+			want: "debug&a=1&b=&v",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -63,31 +68,39 @@ func TestParseParams(t *testing.T) {
 		{
 			name:       "Ampersand as separator",
 			args:       args{"k1=v1&k2=v2"},
-			wantValues: []Param{{"k1", "v1"}, {"k2", "v2"}},
+			wantValues: []Param{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			wantErr:    false,
 		},
 		{
 			name:       "Semicolon as separator",
 			args:       args{"k1=v1;k2=v2"},
-			wantValues: []Param{{"k1", "v1"}, {"k2", "v2"}},
+			wantValues: []Param{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			wantErr:    false,
 		},
 		{
 			name:       "Mixed separators",
 			args:       args{"k1=v1;k2=v2&k3=v3"},
-			wantValues: []Param{{"k1", "v1"}, {"k2", "v2"}, {"k3", "v3"}},
+			wantValues: []Param{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k3", Value: "v3"}},
 			wantErr:    false,
 		},
+		{
+			name: "Flags",
+			args: args{"debug&a=1&b=;verbose"},
+			wantValues: []Param{
+				{Key: "debug", Flag: true}, {Key: "a", Value: "1"}, {Key: "b"}, {Key: "verbose", Flag: true},
+			},
+			wantErr: false,
+		},
 		{
 			name:       "Encoded chars",
 			args:       args{`q=%22daily+news%22`},
-			wantValues: []Param{{"q", `"daily news"`}},
+			wantValues: []Param{{Key: "q", Value: `"daily news"`}},
 			wantErr:    false,
 		},
 		{
 			name:       "Encoded chars err",
 			args:       args{`a=1&q=100%+truth&b=2&brightness=90%`},
-			wantValues: []Param{{"a", "1"}, {"q", "100%+truth"}, {"b", "2"}, {"brightness", "90%"}},
+			wantValues: []Param{{Key: "a", Value: "1"}, {Key: "q", Value: "100%+truth"}, {Key: "b", Value: "2"}, {Key: "brightness", Value: "90%"}},
 			wantErr:    true,
 		},
 	}
@@ -120,18 +133,18 @@ func TestSortOrderParams(t *testing.T) {
 	}{
 		{
 			name:       "No order",
-			args:       args{params: []Param{{"k3", "v3"}, {"k2", "v2"}, {"k1", "v1"}}, order: nil},
-			wantValues: []Param{{"k3", "v3"}, {"k2", "v2"}, {"k1", "v1"}},
+			args:       args{params: []Param{{Key: "k3", Value: "v3"}, {Key: "k2", Value: "v2"}, {Key: "k1", Value: "v1"}}, order: nil},
+			wantValues: []Param{{Key: "k3", Value: "v3"}, {Key: "k2", Value: "v2"}, {Key: "k1", Value: "v1"}},
 		},
 		{
 			name:       "With priority param",
-			args:       args{params: []Param{{"b", "2"}, {"a", "1"}, {"q", "3"}}, order: []string{"q"}},
-			wantValues: []Param{{"q", "3"}, {"b", "2"}, {"a", "1"}},
+			args:       args{params: []Param{{Key: "b", Value: "2"}, {Key: "a", Value: "1"}, {Key: "q", Value: "3"}}, order: []string{"q"}},
+			wantValues: []Param{{Key: "q", Value: "3"}, {Key: "b", Value: "2"}, {Key: "a", Value: "1"}},
 		},
 		{
 			name:       "With full order",
-			args:       args{params: []Param{{"b", "2"}, {"a", "1"}, {"q", "3"}}, order: []string{"q", "a", "b"}},
-			wantValues: []Param{{"q", "3"}, {"a", "1"}, {"b", "2"}},
+			args:       args{params: []Param{{Key: "b", Value: "2"}, {Key: "a", Value: "1"}, {Key: "q", Value: "3"}}, order: []string{"q", "a", "b"}},
+			wantValues: []Param{{Key: "q", Value: "3"}, {Key: "a", Value: "1"}, {Key: "b", Value: "2"}},
 		},
 	}
 	for _, tt := range tests {
@@ -158,13 +171,13 @@ func TestSortParams(t *testing.T) {
 			name: "simple sort",
 			args: args{
 				params: []Param{
-					{"b", "2"},
-					{"a", "2"},
-					{"a", "1"},
-					{"c", "3"},
+					{Key: "b", Value: "2"},
+					{Key: "a", Value: "2"},
+					{Key: "a", Value: "1"},
+					{Key: "c", Value: "3"},
 				},
 			},
-			wantParams: []Param{{"a", "2"}, {"a", "1"}, {"b", "2"}, {"c", "3"}},
+			wantParams: []Param{{Key: "a", Value: "2"}, {Key: "a", Value: "1"}, {Key: "b", Value: "2"}, {Key: "c", Value: "3"}},
 		},
 	}
 	for _, tt := range tests {
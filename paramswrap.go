@@ -13,6 +13,12 @@ func (p *Params) Encode() (encode string) {
 	return EncodeParams(*p)
 }
 
+// EncodeWith is like [Params.Encode], but it escapes keys and values with the given [Escaper].
+// Same as [EncodeParamsWith].
+func (p *Params) EncodeWith(e Escaper) string {
+	return EncodeParamsWith(*p, e)
+}
+
 // Sort sorts the params by key in ascending order. Same as [SortParams].
 func (p *Params) Sort() {
 	SortParams(*p)
@@ -30,51 +36,86 @@ func (p *Params) Add(key string, values ...string) {
 		return
 	}
 	if len(values) == 0 {
-		*p = append(*p, Param{key, ""})
+		*p = append(*p, Param{Key: key})
 		return
 	}
 	for _, value := range values {
-		*p = append(*p, Param{key, value})
+		*p = append(*p, Param{Key: key, Value: value})
 	}
 
 }
 
 // Set sets a param with given key and value.
 // It replaces any existing param with the same key.
+// If the first replaced param was parsed with [Parser.KeepRaw], its original key and separator are kept.
 func (p *Params) Set(key, value string) {
+	p.set(Param{Key: key, Value: value})
+}
+
+// SetFlag sets a valueless param (a bare key) with given key.
+// Like [Params.Set], it replaces any existing param with the same key.
+func (p *Params) SetFlag(key string) {
+	p.set(Param{Key: key, Flag: true})
+}
+
+func (p *Params) set(param Param) {
+	key := param.Key
 	if len(key) == 0 {
 		return
 	}
 
 	foundIdx := -1
+	j := 0
 	for i := 0; i < len(*p); i++ {
 		if (*p)[i].Key == key {
-			if foundIdx == -1 {
-				// remember the first index we found.
-				foundIdx = i
+			if foundIdx != -1 {
+				// in other case simply remove the param.
 				continue
 			}
-			// in other case simply remove the param.
-			*p = append((*p)[:i], (*p)[i+1:]...)
+			// remember the first index we found.
+			foundIdx = j
 		}
+		(*p)[j] = (*p)[i]
+		j++
 	}
+	clearParams((*p)[j:])
+	*p = (*p)[:j]
 
 	if foundIdx > -1 {
-		(*p)[foundIdx] = Param{Key: key, Value: value}
+		// keep the original form of the replaced param, so its separator and key stay untouched.
+		param.raw = (*p)[foundIdx].raw
+		(*p)[foundIdx] = param
 		return
 	}
-	*p = append(*p, Param{Key: key, Value: value})
+	*p = append(*p, param)
+
+}
 
+// HasFlag returns true if the params slice contains a valueless param with given key.
+func (p Params) HasFlag(key string) bool {
+	for _, param := range p {
+		if param.Key == key && param.Flag {
+			return true
+		}
+	}
+	return false
 }
 
 // Get returns the first value of a param with given key or an empty string if not found.
 func (p *Params) Get(key string) string {
+	value, _ := p.Lookup(key)
+	return value
+}
+
+// Lookup returns the first value of a param with given key
+// and reports whether the param is present, so an absent param can be told apart from an empty one.
+func (p *Params) Lookup(key string) (string, bool) {
 	for _, param := range *p {
 		if param.Key == key {
-			return param.Value
+			return param.Value, true
 		}
 	}
-	return ""
+	return "", false
 }
 
 // GetAll returns all values for the given key, if not found returns nil.
@@ -90,24 +131,33 @@ func (p *Params) GetAll(key string) []string {
 
 // Extract removes the first param with given key from the params slice and returns its value.
 func (p *Params) Extract(key string) (value string) {
+	value, _ = p.LookupExtract(key)
+	return value
+}
+
+// LookupExtract removes the first param with given key from the params slice and returns its value.
+// It also reports whether the param was present and removed.
+func (p *Params) LookupExtract(key string) (value string, found bool) {
 	for i := 0; i < len(*p); i++ {
 		if (*p)[i].Key == key {
 			value = (*p)[i].Value
 			*p = append((*p)[:i], (*p)[i+1:]...)
-			return value
+			return value, true
 		}
 	}
-	return ""
+	return "", false
 }
 
 // ExtractAll removes all params with given key from the params slice and returns their values.
+// The returned slice is nil only if nothing was removed.
 func (p *Params) ExtractAll(key string) (values []string) {
-	for i := 0; i < len(*p); i++ {
-		if (*p)[i].Key == key {
-			values = append(values, (*p)[i].Value)
-			*p = append((*p)[:i], (*p)[i+1:]...)
+	p.deleteFunc(func(param Param) bool {
+		if param.Key == key {
+			values = append(values, param.Value)
+			return true
 		}
-	}
+		return false
+	})
 	return values
 }
 
@@ -123,10 +173,40 @@ func (p *Params) Delete(key string) {
 
 // DeleteAll removes all params with given key from the params slice
 func (p *Params) DeleteAll(key string) {
+	p.deleteFunc(func(param Param) bool {
+		return param.Key == key
+	})
+}
+
+// DeleteFunc removes all params whose keys match, in a single pass.
+// The rules, like [PrefixRule], [GlobRule] and [RegexpRule], are matchers too: `p.DeleteFunc(GlobRule("utm_*").Match)`.
+func (p *Params) DeleteFunc(match func(key string) bool) {
+	p.deleteFunc(func(param Param) bool { return match(param.Key) })
+}
+
+// KeepFunc removes all params whose keys don't match, in a single pass.
+func (p *Params) KeepFunc(match func(key string) bool) {
+	p.deleteFunc(func(param Param) bool { return !match(param.Key) })
+}
+
+// deleteFunc removes all params for which del returns true, in a single pass.
+func (p *Params) deleteFunc(del func(Param) bool) {
+	j := 0
 	for i := 0; i < len(*p); i++ {
-		if (*p)[i].Key == key {
-			*p = append((*p)[:i], (*p)[i+1:]...)
+		if del((*p)[i]) {
+			continue
 		}
+		(*p)[j] = (*p)[i]
+		j++
+	}
+	clearParams((*p)[j:])
+	*p = (*p)[:j]
+}
+
+// clearParams zeroes the params left behind the end of a shrunk slice, so they can be garbage collected.
+func clearParams(params []Param) {
+	for i := range params {
+		params[i] = Param{}
 	}
 }
 
@@ -13,20 +13,20 @@ func TestParams_Encode(t *testing.T) {
 	}{
 		{name: "No params", p: Params{}, wantEncode: ""},
 		{name: "No params nil", p: nil, wantEncode: ""},
-		{name: "Simple", p: Params{{"a", "1"}}, wantEncode: "a=1"},
+		{name: "Simple", p: Params{{Key: "a", Value: "1"}}, wantEncode: "a=1"},
 		{
 			name:       "Unordered multiple values",
-			p:          Params{{"a", "1"}, {"b", "2"}, {"a", "3"}},
+			p:          Params{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}, {Key: "a", Value: "3"}},
 			wantEncode: "a=1&b=2&a=3",
 		},
 		{
 			name:       "Encoded chars",
-			p:          Params{{"q", `"daily news"`}},
+			p:          Params{{Key: "q", Value: `"daily news"`}},
 			wantEncode: "q=%22daily+news%22",
 		},
 		{
 			name:       "Previously bad encoded",
-			p:          Params{{"q", `100%+truth`}},
+			p:          Params{{Key: "q", Value: `100%+truth`}},
 			wantEncode: "q=100%25%2Btruth",
 		},
 	}
@@ -58,31 +58,31 @@ func TestParseQuery(t *testing.T) {
 		{
 			name:    "Ampersand as separator",
 			args:    args{"k1=v1&k2=v2"},
-			wantP:   Params{{"k1", "v1"}, {"k2", "v2"}},
+			wantP:   Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			wantErr: false,
 		},
 		{
 			name:    "Semicolon as separator",
 			args:    args{"k1=v1;k2=v2"},
-			wantP:   Params{{"k1", "v1"}, {"k2", "v2"}},
+			wantP:   Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			wantErr: false,
 		},
 		{
 			name:    "Mixed separators",
 			args:    args{"k1=v1;k2=v2&k3=v3"},
-			wantP:   Params{{"k1", "v1"}, {"k2", "v2"}, {"k3", "v3"}},
+			wantP:   Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k3", Value: "v3"}},
 			wantErr: false,
 		},
 		{
 			name:    "Encoded chars",
 			args:    args{`q=%22daily+news%22`},
-			wantP:   Params{{"q", `"daily news"`}},
+			wantP:   Params{{Key: "q", Value: `"daily news"`}},
 			wantErr: false,
 		},
 		{
 			name:    "Encoded chars err",
 			args:    args{`a=1&q=100%+truth&b=2&brightness=90%`},
-			wantP:   Params{{"a", "1"}, {"q", "100%+truth"}, {"b", "2"}, {"brightness", "90%"}},
+			wantP:   Params{{Key: "a", Value: "1"}, {Key: "q", Value: "100%+truth"}, {Key: "b", Value: "2"}, {Key: "brightness", Value: "90%"}},
 			wantErr: true,
 		},
 	}
@@ -108,8 +108,8 @@ func TestParams_Sort(t *testing.T) {
 	}{
 		{
 			name:  "Simple",
-			p:     Params{{"b", "2"}, {"a", "2"}, {"a", "1"}, {"c", "3"}},
-			wantP: Params{{"a", "2"}, {"a", "1"}, {"b", "2"}, {"c", "3"}},
+			p:     Params{{Key: "b", Value: "2"}, {Key: "a", Value: "2"}, {Key: "a", Value: "1"}, {Key: "c", Value: "3"}},
+			wantP: Params{{Key: "a", Value: "2"}, {Key: "a", Value: "1"}, {Key: "b", Value: "2"}, {Key: "c", Value: "3"}},
 		},
 	}
 	for _, tt := range tests {
@@ -135,26 +135,26 @@ func TestParams_SetOrder(t *testing.T) {
 		{
 			name:  "No order",
 			args:  args{order: nil},
-			p:     Params{{"k3", "v3"}, {"k2", "v2"}, {"k1", "v1"}},
-			wantP: Params{{"k3", "v3"}, {"k2", "v2"}, {"k1", "v1"}},
+			p:     Params{{Key: "k3", Value: "v3"}, {Key: "k2", Value: "v2"}, {Key: "k1", Value: "v1"}},
+			wantP: Params{{Key: "k3", Value: "v3"}, {Key: "k2", Value: "v2"}, {Key: "k1", Value: "v1"}},
 		},
 		{
 			name:  "With priority param",
 			args:  args{order: []string{"q"}},
-			p:     Params{{"b", "2"}, {"a", "1"}, {"q", "3"}},
-			wantP: Params{{"q", "3"}, {"b", "2"}, {"a", "1"}},
+			p:     Params{{Key: "b", Value: "2"}, {Key: "a", Value: "1"}, {Key: "q", Value: "3"}},
+			wantP: Params{{Key: "q", Value: "3"}, {Key: "b", Value: "2"}, {Key: "a", Value: "1"}},
 		},
 		{
 			name:  "With full order",
 			args:  args{order: []string{"q", "a", "b"}},
-			p:     Params{{"b", "2"}, {"a", "1"}, {"q", "3"}},
-			wantP: Params{{"q", "3"}, {"a", "1"}, {"b", "2"}},
+			p:     Params{{Key: "b", Value: "2"}, {Key: "a", Value: "1"}, {Key: "q", Value: "3"}},
+			wantP: Params{{Key: "q", Value: "3"}, {Key: "a", Value: "1"}, {Key: "b", Value: "2"}},
 		},
 		{
 			name:  "With Same keys",
 			args:  args{order: []string{"a", "a", "a", "b"}},
-			p:     Params{{"b", "2"}, {"a", "1"}, {"q", "3"}, {"a", "4"}, {"a", "5"}},
-			wantP: Params{{"a", "1"}, {"a", "4"}, {"a", "5"}, {"b", "2"}, {"q", "3"}},
+			p:     Params{{Key: "b", Value: "2"}, {Key: "a", Value: "1"}, {Key: "q", Value: "3"}, {Key: "a", Value: "4"}, {Key: "a", Value: "5"}},
+			wantP: Params{{Key: "a", Value: "1"}, {Key: "a", Value: "4"}, {Key: "a", Value: "5"}, {Key: "b", Value: "2"}, {Key: "q", Value: "3"}},
 		},
 	}
 	for _, tt := range tests {
@@ -180,27 +180,27 @@ func TestParams_Add(t *testing.T) {
 	}{
 		{
 			name:  "Simple",
-			p:     Params{{"k1", "v1"}, {"k2", "v2"}},
+			p:     Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			args:  args{"k3", []string{"v3"}},
-			wantP: Params{{"k1", "v1"}, {"k2", "v2"}, {"k3", "v3"}},
+			wantP: Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k3", Value: "v3"}},
 		},
 		{
 			name:  "Add multiple values",
-			p:     Params{{"k1", "v1"}, {"k2", "v2"}},
+			p:     Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			args:  args{"k3", []string{"v3", "v4"}},
-			wantP: Params{{"k1", "v1"}, {"k2", "v2"}, {"k3", "v3"}, {"k3", "v4"}},
+			wantP: Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k3", Value: "v3"}, {Key: "k3", Value: "v4"}},
 		},
 		{
 			name:  "empty params no value",
 			p:     Params{},
 			args:  args{"k3", []string{""}},
-			wantP: Params{{"k3", ""}},
+			wantP: Params{{Key: "k3", Value: ""}},
 		},
 		{
 			name:  "empty params with no value",
 			p:     Params{},
 			args:  args{"k3", []string{}},
-			wantP: Params{{"k3", ""}},
+			wantP: Params{{Key: "k3", Value: ""}},
 		},
 		{
 			name:  "empty params with no key",
@@ -231,13 +231,13 @@ func TestParams_Get(t *testing.T) {
 	}{
 		{
 			name: "Not found",
-			p:    Params{{"k1", "v1"}, {"k2", "v2"}},
+			p:    Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			args: args{"k3"},
 			want: "",
 		},
 		{
 			name: "Found",
-			p:    Params{{"k1", "v1"}, {"k2", "v2"}},
+			p:    Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			args: args{"k2"},
 			want: "v2",
 		},
@@ -263,14 +263,14 @@ func TestParams_GetAll(t *testing.T) {
 	}{
 		{
 			name: "Not found",
-			p:    Params{{"k1", "v1"}, {"k2", "v2"}},
+			p:    Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			args: args{"k3"},
 			want: nil,
 		},
 		{
 			name: "Found",
 			args: args{"k2"},
-			p:    Params{{"k1", "v1"}, {"k2", "v2"}, {"k2", "v3"}},
+			p:    Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k2", Value: "v3"}},
 			want: []string{"v2", "v3"},
 		},
 	}
@@ -297,23 +297,23 @@ func TestParams_Extract(t *testing.T) {
 	}{
 		{
 			name:      "Not found",
-			p:         Params{{"k1", "v1"}, {"k2", "v2"}},
+			p:         Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			args:      args{"k3"},
-			wantP:     Params{{"k1", "v1"}, {"k2", "v2"}},
+			wantP:     Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			wantValue: "",
 		},
 		{
 			name:      "Found",
-			p:         Params{{"k1", "v1"}, {"k2", "v2"}, {"k3", "v3"}},
+			p:         Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k3", Value: "v3"}},
 			args:      args{"k2"},
-			wantP:     Params{{"k1", "v1"}, {"k3", "v3"}},
+			wantP:     Params{{Key: "k1", Value: "v1"}, {Key: "k3", Value: "v3"}},
 			wantValue: "v2",
 		},
 		{
 			name:      "Found many",
-			p:         Params{{"k1", "v1"}, {"k2", "v2"}, {"k3", "v3"}, {"k2", "v4"}},
+			p:         Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k3", Value: "v3"}, {Key: "k2", Value: "v4"}},
 			args:      args{"k2"},
-			wantP:     Params{{"k1", "v1"}, {"k3", "v3"}, {"k2", "v4"}},
+			wantP:     Params{{Key: "k1", Value: "v1"}, {Key: "k3", Value: "v3"}, {Key: "k2", Value: "v4"}},
 			wantValue: "v2",
 		},
 	}
@@ -343,18 +343,25 @@ func TestParams_ExtractAll(t *testing.T) {
 	}{
 		{
 			name:       "Not found",
-			p:          Params{{"k1", "v1"}, {"k2", "v2"}},
+			p:          Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			args:       args{"k3"},
-			wantP:      Params{{"k1", "v1"}, {"k2", "v2"}},
+			wantP:      Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			wantValues: nil,
 		},
 		{
 			name:       "Found",
-			p:          Params{{"k1", "v1"}, {"k2", "v2"}, {"k3", "v3"}, {"k2", "v4"}},
+			p:          Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k3", Value: "v3"}, {Key: "k2", Value: "v4"}},
 			args:       args{"k2"},
-			wantP:      Params{{"k1", "v1"}, {"k3", "v3"}},
+			wantP:      Params{{Key: "k1", Value: "v1"}, {Key: "k3", Value: "v3"}},
 			wantValues: []string{"v2", "v4"},
 		},
+		{
+			name:       "Found consecutive",
+			p:          Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k2", Value: "v3"}, {Key: "k3", Value: "v4"}},
+			args:       args{"k2"},
+			wantP:      Params{{Key: "k1", Value: "v1"}, {Key: "k3", Value: "v4"}},
+			wantValues: []string{"v2", "v3"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -382,27 +389,33 @@ func TestParams_Set(t *testing.T) {
 	}{
 		{
 			name:  "new param",
-			p:     Params{{"k1", "v1"}, {"k2", "v2"}},
-			wantP: Params{{"k1", "v1"}, {"k2", "v2"}, {"k3", "v3"}},
+			p:     Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
+			wantP: Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k3", Value: "v3"}},
 			args:  args{"k3", "v3"},
 		},
 		{
 			name:  "replace existing",
-			p:     Params{{"k1", "v1"}, {"k2", "v2"}},
-			wantP: Params{{"k1", "v1"}, {"k2", "v3"}},
+			p:     Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
+			wantP: Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v3"}},
 			args:  args{"k2", "v3"},
 		},
 		{
 			name:  "replace existing multiple values",
-			p:     Params{{"k1", "v1"}, {"k2", "v2"}, {"k2", "v3"}, {"k3", "v3"}, {"k2", "v4"}},
-			wantP: Params{{"k1", "v1"}, {"k2", "v4"}, {"k3", "v3"}},
+			p:     Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k2", Value: "v3"}, {Key: "k3", Value: "v3"}, {Key: "k2", Value: "v4"}},
+			wantP: Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v4"}, {Key: "k3", Value: "v3"}},
 			args:  args{"k2", "v4"},
 		},
+		{
+			name:  "replace existing consecutive values",
+			p:     Params{{Key: "k2", Value: "v2"}, {Key: "k2", Value: "v3"}, {Key: "k2", Value: "v4"}, {Key: "k1", Value: "v1"}},
+			wantP: Params{{Key: "k2", Value: "v5"}, {Key: "k1", Value: "v1"}},
+			args:  args{"k2", "v5"},
+		},
 		{
 			name:  "empty key",
 			args:  args{"", "value"},
-			p:     Params{{"k1", "v1"}},
-			wantP: Params{{"k1", "v1"}},
+			p:     Params{{Key: "k1", Value: "v1"}},
+			wantP: Params{{Key: "k1", Value: "v1"}},
 		},
 	}
 	for _, tt := range tests {
@@ -428,21 +441,21 @@ func TestParams_Delete(t *testing.T) {
 	}{
 		{
 			name:  "Not found",
-			p:     Params{{"k1", "v1"}, {"k2", "v2"}},
+			p:     Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			args:  args{"k3"},
-			wantP: Params{{"k1", "v1"}, {"k2", "v2"}},
+			wantP: Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 		},
 		{
 			name:  "Found",
-			p:     Params{{"k1", "v1"}, {"k2", "v2"}, {"k3", "v3"}},
+			p:     Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k3", Value: "v3"}},
 			args:  args{"k2"},
-			wantP: Params{{"k1", "v1"}, {"k3", "v3"}},
+			wantP: Params{{Key: "k1", Value: "v1"}, {Key: "k3", Value: "v3"}},
 		},
 		{
 			name:  "Found many",
-			p:     Params{{"k1", "v1"}, {"k2", "v2"}, {"k3", "v3"}, {"k2", "v4"}},
+			p:     Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k3", Value: "v3"}, {Key: "k2", Value: "v4"}},
 			args:  args{"k2"},
-			wantP: Params{{"k1", "v1"}, {"k3", "v3"}, {"k2", "v4"}},
+			wantP: Params{{Key: "k1", Value: "v1"}, {Key: "k3", Value: "v3"}, {Key: "k2", Value: "v4"}},
 		},
 	}
 	for _, tt := range tests {
@@ -468,21 +481,27 @@ func TestParams_DeleteAll(t *testing.T) {
 	}{
 		{
 			name:  "Not found",
-			p:     Params{{"k1", "v1"}, {"k2", "v2"}},
+			p:     Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			args:  args{"k3"},
-			wantP: Params{{"k1", "v1"}, {"k2", "v2"}},
+			wantP: Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 		},
 		{
 			name:  "Found",
-			p:     Params{{"k1", "v1"}, {"k2", "v2"}, {"k3", "v3"}},
+			p:     Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k3", Value: "v3"}},
 			args:  args{"k2"},
-			wantP: Params{{"k1", "v1"}, {"k3", "v3"}},
+			wantP: Params{{Key: "k1", Value: "v1"}, {Key: "k3", Value: "v3"}},
 		},
 		{
 			name:  "Found many",
-			p:     Params{{"k1", "v1"}, {"k2", "v2"}, {"k3", "v3"}, {"k2", "v4"}},
+			p:     Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k3", Value: "v3"}, {Key: "k2", Value: "v4"}},
+			args:  args{"k2"},
+			wantP: Params{{Key: "k1", Value: "v1"}, {Key: "k3", Value: "v3"}},
+		},
+		{
+			name:  "Found consecutive",
+			p:     Params{{Key: "k2", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k3", Value: "v3"}, {Key: "k2", Value: "v4"}, {Key: "k2", Value: "v5"}},
 			args:  args{"k2"},
-			wantP: Params{{"k1", "v1"}, {"k3", "v3"}},
+			wantP: Params{{Key: "k3", Value: "v3"}},
 		},
 	}
 	for _, tt := range tests {
@@ -496,6 +515,50 @@ func TestParams_DeleteAll(t *testing.T) {
 	}
 }
 
+func TestParams_DeleteFunc(t *testing.T) {
+	p := Params{{Key: "utm_source", Value: "x"}, {Key: "q", Value: "go"}, {Key: "user_id", Value: "1"}, {Key: "debug", Flag: true}, {Key: "q", Value: "rust"}}
+
+	tests := []struct {
+		name     string
+		match    func(key string) bool
+		wantDel  Params
+		wantKeep Params
+	}{
+		{
+			name:     "Glob",
+			match:    GlobRule("utm_*", "*_id").Match,
+			wantDel:  Params{{Key: "q", Value: "go"}, {Key: "debug", Flag: true}, {Key: "q", Value: "rust"}},
+			wantKeep: Params{{Key: "utm_source", Value: "x"}, {Key: "user_id", Value: "1"}},
+		},
+		{
+			name:     "Exact",
+			match:    ExactRule("q").Match,
+			wantDel:  Params{{Key: "utm_source", Value: "x"}, {Key: "user_id", Value: "1"}, {Key: "debug", Flag: true}},
+			wantKeep: Params{{Key: "q", Value: "go"}, {Key: "q", Value: "rust"}},
+		},
+		{
+			name:     "None",
+			match:    func(string) bool { return false },
+			wantDel:  p,
+			wantKeep: Params{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := append(Params(nil), p...)
+			got.DeleteFunc(tt.match)
+			if !reflect.DeepEqual(got, tt.wantDel) {
+				t.Errorf("Params.DeleteFunc() = %v, want %v", got, tt.wantDel)
+			}
+			got = append(Params(nil), p...)
+			got.KeepFunc(tt.match)
+			if !reflect.DeepEqual(got, tt.wantKeep) {
+				t.Errorf("Params.KeepFunc() = %v, want %v", got, tt.wantKeep)
+			}
+		})
+	}
+}
+
 func TestParams_Has(t *testing.T) {
 	type args struct {
 		key string
@@ -508,13 +571,13 @@ func TestParams_Has(t *testing.T) {
 	}{
 		{
 			name: "Found",
-			p:    Params{{"k1", "v1"}, {"k2", "v2"}},
+			p:    Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			args: args{"k2"},
 			want: true,
 		},
 		{
 			name: "Not found",
-			p:    Params{{"k1", "v1"}, {"k2", "v2"}},
+			p:    Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}},
 			args: args{"k3"},
 			want: false,
 		},
@@ -527,3 +590,159 @@ func TestParams_Has(t *testing.T) {
 		})
 	}
 }
+
+func TestParams_SetFlag(t *testing.T) {
+	tests := []struct {
+		name  string
+		p     Params
+		key   string
+		wantP Params
+	}{
+		{
+			name:  "New flag",
+			p:     Params{{Key: "k1", Value: "v1"}},
+			key:   "debug",
+			wantP: Params{{Key: "k1", Value: "v1"}, {Key: "debug", Flag: true}},
+		},
+		{
+			name:  "Replace existing params",
+			p:     Params{{Key: "debug", Value: "1"}, {Key: "k1", Value: "v1"}, {Key: "debug", Value: "2"}},
+			key:   "debug",
+			wantP: Params{{Key: "debug", Flag: true}, {Key: "k1", Value: "v1"}},
+		},
+		{
+			name:  "No key",
+			p:     Params{{Key: "k1", Value: "v1"}},
+			key:   "",
+			wantP: Params{{Key: "k1", Value: "v1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.p.SetFlag(tt.key)
+			if !reflect.DeepEqual(tt.p, tt.wantP) {
+				t.Errorf("Params.SetFlag() = %v, want %v", tt.p, tt.wantP)
+			}
+		})
+	}
+}
+
+func TestParams_HasFlag(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Params
+		key  string
+		want bool
+	}{
+		{
+			name: "Found",
+			p:    Params{{Key: "k1", Value: "v1"}, {Key: "debug", Flag: true}},
+			key:  "debug",
+			want: true,
+		},
+		{
+			name: "Found with value",
+			p:    Params{{Key: "k1", Value: "v1"}, {Key: "debug"}},
+			key:  "debug",
+			want: false,
+		},
+		{
+			name: "Not found",
+			p:    Params{{Key: "k1", Value: "v1"}},
+			key:  "debug",
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.HasFlag(tt.key); got != tt.want {
+				t.Errorf("Params.HasFlag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParams_Lookup(t *testing.T) {
+	tests := []struct {
+		name      string
+		p         Params
+		key       string
+		wantValue string
+		wantFound bool
+	}{
+		{
+			name:      "Not found",
+			p:         Params{{Key: "k1", Value: "v1"}},
+			key:       "k2",
+			wantValue: "",
+			wantFound: false,
+		},
+		{
+			name:      "Found",
+			p:         Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v2"}, {Key: "k2", Value: "v3"}},
+			key:       "k2",
+			wantValue: "v2",
+			wantFound: true,
+		},
+		{
+			name:      "Found empty",
+			p:         Params{{Key: "k1", Value: "v1"}, {Key: "k2"}},
+			key:       "k2",
+			wantValue: "",
+			wantFound: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotValue, gotFound := tt.p.Lookup(tt.key)
+			if gotValue != tt.wantValue {
+				t.Errorf("Params.Lookup() value = %v, want %v", gotValue, tt.wantValue)
+			}
+			if gotFound != tt.wantFound {
+				t.Errorf("Params.Lookup() found = %v, want %v", gotFound, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestParams_LookupExtract(t *testing.T) {
+	tests := []struct {
+		name      string
+		p         Params
+		key       string
+		wantValue string
+		wantFound bool
+		wantP     Params
+	}{
+		{
+			name:      "Not found",
+			p:         Params{{Key: "k1", Value: "v1"}},
+			key:       "k2",
+			wantValue: "",
+			wantFound: false,
+			wantP:     Params{{Key: "k1", Value: "v1"}},
+		},
+		{
+			name:      "Found empty",
+			p:         Params{{Key: "k1", Value: "v1"}, {Key: "k2"}, {Key: "k2", Value: "v3"}},
+			key:       "k2",
+			wantValue: "",
+			wantFound: true,
+			wantP:     Params{{Key: "k1", Value: "v1"}, {Key: "k2", Value: "v3"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotValue, gotFound := tt.p.LookupExtract(tt.key)
+			if gotValue != tt.wantValue {
+				t.Errorf("Params.LookupExtract() value = %v, want %v", gotValue, tt.wantValue)
+			}
+			if gotFound != tt.wantFound {
+				t.Errorf("Params.LookupExtract() found = %v, want %v", gotFound, tt.wantFound)
+			}
+			if !reflect.DeepEqual(tt.p, tt.wantP) {
+				t.Errorf("Params.LookupExtract() = %v, want %v", tt.p, tt.wantP)
+			}
+		})
+	}
+}
@@ -0,0 +1,404 @@
+package urlqm
+
+import (
+	"strings"
+)
+
+// Parser configures the functions that work with a raw query string.
+// The zero value is ready to use and behaves like the package-level functions,
+// which are shortcuts for the methods of the zero Parser.
+// Keys and values that fail to be decoded are reported as [*ParseError].
+type Parser struct {
+	// Separators is the set of characters that separate params in a query string.
+	// If it is empty, both '&' and ';' are separators.
+	// The first character of the set is used to join new params to the query string.
+	Separators string
+
+	// KeepPlus makes '+' stay a plus sign when keys and values are decoded,
+	// instead of turning into a space. So that the parser can read back what it writes,
+	// it also makes [RFC3986Escaper] the default Escaper, which writes a space as `%20` and '+' as `%2B`.
+	KeepPlus bool
+
+	// Strict makes [Parser.ParseParams] fail on the first malformed param:
+	// a param with an invalid escape sequence or with an empty key.
+	// In that case no params are returned. By default, malformed params are kept as they are,
+	// and all the errors are collected.
+	// The raw-string functions always report an error if they fail to decode a requested value.
+	Strict bool
+
+	// KeepRaw makes [Parser.ParseParams] remember the original form of every param:
+	// its escaped key and value and the separators before it, or after it if it is the last one.
+	// [EncodeParams] re-emits such params verbatim unless they were modified,
+	// so a parse-modify-encode cycle changes only the bytes of the modified params.
+	// The separators at the beginning of the query string are kept as long as the first param stays first,
+	// and the ones at the end as long as the last param stays last.
+	KeepRaw bool
+
+	// Escaper escapes keys and values of the params added by [Parser.AddQueryParam],
+	// [Parser.SetQueryParam] and [Parser.SetQueryFlag]. If it is nil, [FormEscaper] is used,
+	// or [RFC3986Escaper] if KeepPlus is set.
+	Escaper Escaper
+
+	// DecodeKeys makes the functions compare keys in decoded form.
+	// The key must be passed unescaped then, and it matches any encoding of itself,
+	// e.g. "ключ" matches both `%D0%BA%D0%BB...` and `%d0%ba%d0%bb...`, and "a b" matches both `a+b` and `a%20b`.
+	DecodeKeys bool
+}
+
+var defaultParser Parser
+
+// ParseParams takes a query string and returns a slice of Param. See [ParseParams].
+func (p Parser) ParseParams(query string) ([]Param, error) {
+	var errs ParseErrors
+
+	if query == "" {
+		return nil, nil
+	}
+
+	seps := p.separators()
+	estLen := 1
+	for i := 0; i < len(seps); i++ {
+		estLen += strings.Count(query, seps[i:i+1])
+	}
+	params := make([]Param, 0, estLen)
+
+	s := QueryScanner{query: query, parser: p, index: -1}
+	for s.Next() {
+		key, err := s.decodeKey()
+		if err != nil {
+			if p.Strict {
+				return nil, err
+			}
+			errs = append(errs, err)
+		}
+
+		value, err := s.decodeValue()
+		if err != nil {
+			if p.Strict {
+				return nil, err
+			}
+			errs = append(errs, err)
+		}
+
+		param := Param{Key: key, Value: value, Flag: s.Flag()}
+		if p.KeepRaw {
+			param.raw = &rawParam{
+				sep: s.sep(), lead: len(params) == 0, key: s.RawKey(), value: s.RawValue(),
+				decKey: key, decValue: value, flag: param.Flag,
+			}
+		}
+		params = append(params, param)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if p.KeepRaw && len(params) > 0 {
+		params[len(params)-1].raw.trail = query[s.prevEnd:]
+	}
+
+	return params, errs.err()
+}
+
+// ParseQuery takes a query string and returns a slice of [Param]. See [Parser.ParseParams].
+func (p Parser) ParseQuery(rawQuery string) (Params, error) {
+	return p.ParseParams(rawQuery)
+}
+
+// ExtractQueryParam removes and returns the value of a parameter from the query string. See [ExtractQueryParam].
+func (p Parser) ExtractQueryParam(query *string, key string) (value string, err error) {
+	value, _, err = p.LookupExtractQueryParam(query, key)
+	return
+}
+
+// LookupExtractQueryParam is like [Parser.ExtractQueryParam], but it also reports whether the parameter is present.
+// See [LookupExtractQueryParam].
+func (p Parser) LookupExtractQueryParam(query *string, key string) (value string, found bool, err error) {
+	start, end := p.indexParam(*query, key, 0)
+	if start < 0 {
+		return
+	}
+
+	value, err = p.decodeValue(*query, start, end)
+	if err != nil {
+		return value, true, err
+	}
+
+	c := queryCutter{query: *query, seps: p.separators()}
+	c.remove(start, end)
+	*query = c.String()
+	return value, true, nil
+}
+
+// ExtractQueryParamAll removes and returns a slice of values for a parameter from the query string.
+// See [ExtractQueryParamAll].
+func (p Parser) ExtractQueryParamAll(query *string, key string) (values []string, err error) {
+	c := queryCutter{query: *query, seps: p.separators()}
+
+	for start, end := p.indexParam(*query, key, 0); start >= 0; start, end = p.indexParam(*query, key, end) {
+		var value string
+		value, err = p.decodeValue(*query, start, end)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		c.remove(start, end)
+	}
+
+	if values != nil {
+		*query = c.String()
+	}
+
+	return
+}
+
+// GetQueryParam returns the value of a parameter from the query string. See [GetQueryParam].
+func (p Parser) GetQueryParam(query string, key string) (value string, err error) {
+	value, _, err = p.LookupQueryParam(query, key)
+	return
+}
+
+// LookupQueryParam returns the value of a parameter from the query string,
+// and reports whether the parameter is present. See [LookupQueryParam].
+func (p Parser) LookupQueryParam(query string, key string) (value string, found bool, err error) {
+	start, end := p.indexParam(query, key, 0)
+	if start < 0 {
+		return
+	}
+	value, err = p.decodeValue(query, start, end)
+	return value, true, err
+}
+
+// GetQueryParamAll returns the slice of values for a parameter from the query string. See [GetQueryParamAll].
+func (p Parser) GetQueryParamAll(query, key string) (values []string, err error) {
+
+	for start, end := p.indexParam(query, key, 0); start >= 0; start, end = p.indexParam(query, key, end) {
+		var value string
+		if value, err = p.decodeValue(query, start, end); err != nil {
+			return []string{}, err
+		}
+		values = append(values, value)
+	}
+
+	return
+}
+
+// AddQueryParam adds a parameter to the query string. See [AddQueryParam].
+func (p Parser) AddQueryParam(query *string, key string, values ...string) {
+
+	if key == "" {
+		return
+	}
+	buf := strings.Builder{}
+
+	if len(*query) > 0 {
+		buf.WriteString(*query)
+		buf.WriteByte(p.separator())
+	}
+	writeParam(&buf, p.escaper(), string(p.separator()), key, values...)
+	*query = buf.String()
+}
+
+// SetQueryParam sets a parameter in the query string. See [SetQueryParam].
+func (p Parser) SetQueryParam(query *string, key string, value string) {
+	if key == "" {
+		return
+	}
+
+	buf := strings.Builder{}
+	writeParam(&buf, p.escaper(), "", key, value)
+	p.replaceQueryParam(query, key, buf.String())
+}
+
+// SetQueryFlag sets a valueless parameter (a bare key) in the query string. See [SetQueryFlag].
+func (p Parser) SetQueryFlag(query *string, key string) {
+	if key == "" {
+		return
+	}
+	p.replaceQueryParam(query, key, p.escaper().Escape(key))
+}
+
+// replaceQueryParam puts the encoded param in place of the first parameter with the given key
+// and removes the others. If there is no such parameter, the encoded param is appended to the query.
+func (p Parser) replaceQueryParam(query *string, key string, param string) {
+	start, end := p.indexParam(*query, key, 0)
+	if start < 0 {
+		buf := strings.Builder{}
+		buf.WriteString(*query)
+		if buf.Len() > 0 && !isSeparator((*query)[len(*query)-1], p.separators()) {
+			buf.WriteByte(p.separator())
+		}
+		buf.WriteString(param)
+		*query = buf.String()
+		return
+	}
+
+	c := queryCutter{query: *query, seps: p.separators()}
+	c.replace(start, end, param)
+	for start, end = p.indexParam(*query, key, end); start >= 0; start, end = p.indexParam(*query, key, end) {
+		c.remove(start, end)
+	}
+	*query = c.String()
+}
+
+// DeleteQueryParam removes a parameter from the query string by key. See [DeleteQueryParam].
+func (p Parser) DeleteQueryParam(query *string, key string) {
+	start, end := p.indexParam(*query, key, 0)
+	if start < 0 {
+		return
+	}
+
+	c := queryCutter{query: *query, seps: p.separators()}
+	c.remove(start, end)
+	*query = c.String()
+}
+
+// DeleteQueryParamAll removes all parameters from the query string by key. See [DeleteQueryParamAll].
+func (p Parser) DeleteQueryParamAll(query *string, key string) {
+	start, end := p.indexParam(*query, key, 0)
+	if start < 0 {
+		return
+	}
+
+	c := queryCutter{query: *query, seps: p.separators()}
+	for ; start >= 0; start, end = p.indexParam(*query, key, end) {
+		c.remove(start, end)
+	}
+	*query = c.String()
+}
+
+// DeleteQueryParams removes all parameters with any of the given keys from the query string.
+// See [DeleteQueryParams].
+func (p Parser) DeleteQueryParams(query *string, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	p.deleteQueryParamsFunc(query, func(key string) bool { return containsString(keys, key) })
+}
+
+// KeepQueryParams removes all parameters from the query string except the ones with the given keys.
+// See [KeepQueryParams].
+func (p Parser) KeepQueryParams(query *string, keys ...string) {
+	p.deleteQueryParamsFunc(query, func(key string) bool { return !containsString(keys, key) })
+}
+
+// DeleteQueryParamsFunc removes all parameters whose keys match from the query string.
+// See [DeleteQueryParamsFunc].
+func (p Parser) DeleteQueryParamsFunc(query *string, match func(key string) bool) {
+	p.deleteQueryParamsFunc(query, match)
+}
+
+// KeepQueryParamsFunc removes all parameters whose keys don't match from the query string.
+// See [KeepQueryParamsFunc].
+func (p Parser) KeepQueryParamsFunc(query *string, match func(key string) bool) {
+	p.deleteQueryParamsFunc(query, func(key string) bool { return !match(key) })
+}
+
+// deleteQueryParamsFunc removes all parameters whose keys match from the query string, in a single pass.
+// Keys are matched as they are in the query string, or decoded if [Parser.DecodeKeys] is set.
+func (p Parser) deleteQueryParamsFunc(query *string, match func(key string) bool) {
+	var c queryCutter
+	s := QueryScanner{query: *query, parser: p, index: -1}
+	for s.Next() {
+		key := s.RawKey()
+		if p.DecodeKeys {
+			key, _ = s.decodeKey()
+		}
+		if !match(key) {
+			continue
+		}
+		if c.query == "" {
+			c = queryCutter{query: *query, seps: p.separators()}
+		}
+		c.remove(s.start, s.end)
+	}
+	if c.query != "" {
+		*query = c.String()
+	}
+}
+
+// HasQueryParam returns true if the query string contains a parameter with the given key. See [HasQueryParam].
+func (p Parser) HasQueryParam(query string, key string) bool {
+	start, _ := p.indexParam(query, key, 0)
+	return start >= 0
+}
+
+// HasQueryFlag returns true if the query string contains a valueless parameter with the given key.
+// See [HasQueryFlag].
+func (p Parser) HasQueryFlag(query string, key string) bool {
+	start, _ := p.indexFlag(query, key, 0)
+	return start >= 0
+}
+
+// DeleteQueryFlag removes all valueless parameters from the query string by key. See [DeleteQueryFlag].
+func (p Parser) DeleteQueryFlag(query *string, key string) {
+	start, end := p.indexFlag(*query, key, 0)
+	if start < 0 {
+		return
+	}
+
+	c := queryCutter{query: *query, seps: p.separators()}
+	for ; start >= 0; start, end = p.indexFlag(*query, key, end) {
+		c.remove(start, end)
+	}
+	*query = c.String()
+}
+
+// indexParam returns the bounds of the first param in query, starting at offset from,
+// which has the given key. If there is no such param, it returns -1, -1.
+func (p Parser) indexParam(query, key string, from int) (start, end int) {
+	if p.DecodeKeys {
+		return indexParamDecoded(query, key, p.separators(), !p.KeepPlus, from)
+	}
+	return indexParam(query, key, p.separators(), from)
+}
+
+// indexFlag is like indexParam, but it matches only valueless params.
+func (p Parser) indexFlag(query, key string, from int) (start, end int) {
+	for start, end = p.indexParam(query, key, from); start >= 0; start, end = p.indexParam(query, key, end) {
+		if isFlag(query[start:end]) {
+			return
+		}
+	}
+	return -1, -1
+}
+
+// separators returns the set of param separators.
+func (p Parser) separators() string {
+	if p.Separators == "" {
+		return defaultSeparators
+	}
+	return p.Separators
+}
+
+// separator returns the separator that joins new params to a query string.
+func (p Parser) separator() byte {
+	return p.separators()[0]
+}
+
+// decodeValue decodes the value of the param bounded by start and end.
+// If it fails, the returned error is a [*ParseError].
+func (p Parser) decodeValue(query string, start, end int) (string, error) {
+	rawValue := paramValue(query[start:end])
+	value, err := p.unescape(rawValue)
+	if err != nil {
+		rawKey, _, _ := strings.Cut(query[start:end], "=")
+		return "", &ParseError{
+			Offset: start, Index: paramIndex(query[:start], p.separators()),
+			Key: rawKey, Value: rawValue, Part: PartValue, Err: err,
+		}
+	}
+	return value, nil
+}
+
+// escaper returns the escaper for new keys and values.
+func (p Parser) escaper() Escaper {
+	if p.Escaper == nil && p.KeepPlus {
+		return RFC3986Escaper
+	}
+	return escaperOrDefault(p.Escaper)
+}
+
+// unescape decodes an escaped key or value.
+func (p Parser) unescape(s string) (string, error) {
+	return unescape(s, !p.KeepPlus)
+}
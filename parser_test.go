@@ -0,0 +1,390 @@
+package urlqm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParser_DecodeKeys(t *testing.T) {
+	p := Parser{DecodeKeys: true}
+	tests := []struct {
+		name       string
+		query      string
+		key        string
+		wantValues []string
+		wantQuery  string
+	}{
+		{
+			name:       "Upper case hex",
+			query:      "a=1&%D0%BA%D0%BB%D1%8E%D1%87=2",
+			key:        "ключ",
+			wantValues: []string{"2"},
+			wantQuery:  "a=1",
+		},
+		{
+			name:       "Mixed case hex",
+			query:      "%D0%BA%D0%BB%D1%8E%D1%87=1&a=2&%d0%ba%d0%bb%d1%8e%d1%87=3",
+			key:        "ключ",
+			wantValues: []string{"1", "3"},
+			wantQuery:  "a=2",
+		},
+		{
+			name:       "Plus and %20 for space",
+			query:      "a+b=1;c=2;a%20b=3",
+			key:        "a b",
+			wantValues: []string{"1", "3"},
+			wantQuery:  "c=2",
+		},
+		{
+			name:       "Not encoded key",
+			query:      "uid=1&id=2&id",
+			key:        "id",
+			wantValues: []string{"2", ""},
+			wantQuery:  "uid=1",
+		},
+		{
+			name:       "Encoded key does not match its escaped form",
+			query:      "%D0%BA%D0%BB%D1%8E%D1%87=1",
+			key:        "%D0%BA%D0%BB%D1%8E%D1%87",
+			wantValues: nil,
+			wantQuery:  "%D0%BA%D0%BB%D1%8E%D1%87=1",
+		},
+		{
+			name:       "Bad key encoding is compared as is",
+			query:      "a%=1&b=2",
+			key:        "a%",
+			wantValues: []string{"1"},
+			wantQuery:  "b=2",
+		},
+		{
+			name:       "Longer decoded key",
+			query:      "%D0%BA%D0%BB%D1%8E%D1%87%D1%96=1",
+			key:        "ключ",
+			wantValues: nil,
+			wantQuery:  "%D0%BA%D0%BB%D1%8E%D1%87%D1%96=1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotValues, err := p.GetQueryParamAll(tt.query, tt.key)
+			if err != nil {
+				t.Errorf("Parser.GetQueryParamAll() error = %v", err)
+			}
+			if !reflect.DeepEqual(gotValues, tt.wantValues) {
+				t.Errorf("Parser.GetQueryParamAll() = %v, want %v", gotValues, tt.wantValues)
+			}
+			if got := p.HasQueryParam(tt.query, tt.key); got != (tt.wantValues != nil) {
+				t.Errorf("Parser.HasQueryParam() = %v, want %v", got, tt.wantValues != nil)
+			}
+
+			query := tt.query
+			gotValues, err = p.ExtractQueryParamAll(&query, tt.key)
+			if err != nil {
+				t.Errorf("Parser.ExtractQueryParamAll() error = %v", err)
+			}
+			if !reflect.DeepEqual(gotValues, tt.wantValues) {
+				t.Errorf("Parser.ExtractQueryParamAll() = %v, want %v", gotValues, tt.wantValues)
+			}
+			if query != tt.wantQuery {
+				t.Errorf("Parser.ExtractQueryParamAll() query = %v, want %v", query, tt.wantQuery)
+			}
+
+			query = tt.query
+			p.DeleteQueryParamAll(&query, tt.key)
+			if query != tt.wantQuery {
+				t.Errorf("Parser.DeleteQueryParamAll() query = %v, want %v", query, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestParser_DecodeKeysSet(t *testing.T) {
+	p := Parser{DecodeKeys: true}
+	query := "%d0%ba%d0%bb%d1%8e%d1%87=1&a=2&%D0%BA%D0%BB%D1%8E%D1%87=3"
+
+	p.SetQueryParam(&query, "ключ", "4")
+	want := "%D0%BA%D0%BB%D1%8E%D1%87=4&a=2"
+	if query != want {
+		t.Errorf("Parser.SetQueryParam() query = %v, want %v", query, want)
+	}
+
+	p.SetQueryFlag(&query, "ключ")
+	want = "%D0%BA%D0%BB%D1%8E%D1%87&a=2"
+	if query != want {
+		t.Errorf("Parser.SetQueryFlag() query = %v, want %v", query, want)
+	}
+	if !p.HasQueryFlag(query, "ключ") {
+		t.Errorf("Parser.HasQueryFlag() = false, want true")
+	}
+
+	p.DeleteQueryFlag(&query, "ключ")
+	if query != "a=2" {
+		t.Errorf("Parser.DeleteQueryFlag() query = %v, want %v", query, "a=2")
+	}
+}
+
+func TestParser_ParseParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		parser     Parser
+		query      string
+		wantValues []Param
+		wantErr    bool
+	}{
+		{
+			name:       "Default",
+			parser:     Parser{},
+			query:      "a=1;b=x+y&c=3",
+			wantValues: []Param{{Key: "a", Value: "1"}, {Key: "b", Value: "x y"}, {Key: "c", Value: "3"}},
+		},
+		{
+			name:       "Semicolon is data",
+			parser:     Parser{Separators: "&"},
+			query:      "a=1;b=2&c=3",
+			wantValues: []Param{{Key: "a", Value: "1;b=2"}, {Key: "c", Value: "3"}},
+		},
+		{
+			name:       "Semicolon only",
+			parser:     Parser{Separators: ";"},
+			query:      "a=1&b=2;c=3",
+			wantValues: []Param{{Key: "a", Value: "1&b=2"}, {Key: "c", Value: "3"}},
+		},
+		{
+			name:       "Keep plus",
+			parser:     Parser{KeepPlus: true},
+			query:      "a+b=1+2&c=%20%2B",
+			wantValues: []Param{{Key: "a+b", Value: "1+2"}, {Key: "c", Value: " +"}},
+		},
+		{
+			name:       "Lenient",
+			parser:     Parser{},
+			query:      "a=1&q=100%&=3",
+			wantValues: []Param{{Key: "a", Value: "1"}, {Key: "q", Value: "100%"}, {Key: "", Value: "3"}},
+			wantErr:    true,
+		},
+		{
+			name:       "Strict bad escape",
+			parser:     Parser{Strict: true},
+			query:      "a=1&q=100%&b=2",
+			wantValues: nil,
+			wantErr:    true,
+		},
+		{
+			name:       "Strict empty key",
+			parser:     Parser{Strict: true},
+			query:      "a=1&=2",
+			wantValues: nil,
+			wantErr:    true,
+		},
+		{
+			name:       "Strict valid",
+			parser:     Parser{Strict: true},
+			query:      "a=1&&b",
+			wantValues: []Param{{Key: "a", Value: "1"}, {Key: "b", Flag: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotValues, err := tt.parser.ParseParams(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parser.ParseParams() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotValues, tt.wantValues) {
+				t.Errorf("Parser.ParseParams() = %v, want %v", gotValues, tt.wantValues)
+			}
+		})
+	}
+}
+
+func TestParser_Separators(t *testing.T) {
+	p := Parser{Separators: "&"}
+	query := "a=1;id=2&id=3"
+
+	if values, _ := p.GetQueryParamAll(query, "id"); !reflect.DeepEqual(values, []string{"3"}) {
+		t.Errorf("Parser.GetQueryParamAll() = %v, want %v", values, []string{"3"})
+	}
+	if value, _ := p.GetQueryParam(query, "a"); value != "1;id=2" {
+		t.Errorf("Parser.GetQueryParam() = %v, want %v", value, "1;id=2")
+	}
+
+	q := query
+	p.DeleteQueryParam(&q, "id")
+	if q != "a=1;id=2" {
+		t.Errorf("Parser.DeleteQueryParam() query = %v, want %v", q, "a=1;id=2")
+	}
+
+	p = Parser{Separators: ";"}
+	q = "a=1;b=2"
+	p.AddQueryParam(&q, "c", "3", "4")
+	if q != "a=1;b=2;c=3;c=4" {
+		t.Errorf("Parser.AddQueryParam() query = %v, want %v", q, "a=1;b=2;c=3;c=4")
+	}
+	p.SetQueryParam(&q, "d", "5")
+	if q != "a=1;b=2;c=3;c=4;d=5" {
+		t.Errorf("Parser.SetQueryParam() query = %v, want %v", q, "a=1;b=2;c=3;c=4;d=5")
+	}
+}
+
+func TestParser_KeepPlus(t *testing.T) {
+	p := Parser{KeepPlus: true}
+	query := "q=1+2&a+b=3&a%20b=4"
+
+	if value, _ := p.GetQueryParam(query, "q"); value != "1+2" {
+		t.Errorf("Parser.GetQueryParam() = %v, want %v", value, "1+2")
+	}
+
+	p.DecodeKeys = true
+	if values, _ := p.GetQueryParamAll(query, "a b"); !reflect.DeepEqual(values, []string{"4"}) {
+		t.Errorf("Parser.GetQueryParamAll() = %v, want %v", values, []string{"4"})
+	}
+	if values, _ := p.GetQueryParamAll(query, "a+b"); !reflect.DeepEqual(values, []string{"3"}) {
+		t.Errorf("Parser.GetQueryParamAll() = %v, want %v", values, []string{"3"})
+	}
+
+	// the parser reads back what it writes.
+	p = Parser{KeepPlus: true}
+	query = "q=1"
+	p.SetQueryParam(&query, "q", "x y+z")
+	p.AddQueryParam(&query, "a b", "1+1")
+	p.SetQueryFlag(&query, "c+d")
+	if want := "q=x%20y%2Bz&a%20b=1%2B1&c%2Bd"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+	if value, _ := p.GetQueryParam(query, "q"); value != "x y+z" {
+		t.Errorf("Parser.GetQueryParam() = %q, want %q", value, "x y+z")
+	}
+	p.DecodeKeys = true
+	if value, _ := p.GetQueryParam(query, "a b"); value != "1+1" {
+		t.Errorf("Parser.GetQueryParam() = %q, want %q", value, "1+1")
+	}
+	if !p.HasQueryFlag(query, "c+d") {
+		t.Errorf("Parser.HasQueryFlag() = false, want true")
+	}
+}
+
+func TestParser_KeepRaw(t *testing.T) {
+	const query = "b=x%20y;a=%7e&debug&&c=1+2;q=100%+truth"
+	tests := []struct {
+		name   string
+		modify func(p *Params)
+		want   string
+	}{
+		{
+			name:   "Untouched",
+			modify: func(p *Params) {},
+			want:   query,
+		},
+		{
+			name:   "Set value",
+			modify: func(p *Params) { p.Set("a", "~ b") },
+			want:   "b=x%20y;a=~+b&debug&&c=1+2;q=100%+truth",
+		},
+		{
+			name:   "Modify value in place",
+			modify: func(p *Params) { (*p)[4].Value = "100%" },
+			want:   "b=x%20y;a=%7e&debug&&c=1+2;q=100%25",
+		},
+		{
+			name:   "Modify key in place",
+			modify: func(p *Params) { (*p)[0].Key = "b b" },
+			want:   "b+b=x%20y;a=%7e&debug&&c=1+2;q=100%+truth",
+		},
+		{
+			name:   "Set flag value",
+			modify: func(p *Params) { p.Set("debug", "") },
+			want:   "b=x%20y;a=%7e&debug=&&c=1+2;q=100%+truth",
+		},
+		{
+			name:   "Delete",
+			modify: func(p *Params) { p.Delete("debug") },
+			want:   "b=x%20y;a=%7e&&c=1+2;q=100%+truth",
+		},
+		{
+			name:   "Add",
+			modify: func(p *Params) { p.Add("d", "4 5") },
+			want:   query + ";d=4+5",
+		},
+		{
+			name:   "Sort",
+			modify: func(p *Params) { p.Sort() },
+			want:   "a=%7e;b=x%20y&&c=1+2&debug;q=100%+truth",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parser{KeepRaw: true}.ParseQuery(query)
+			if err == nil {
+				t.Errorf("Parser.ParseQuery() error = nil, want an escape error")
+			}
+			tt.modify(&p)
+			if got := p.Encode(); got != tt.want {
+				t.Errorf("Params.Encode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_KeepRawEdges(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		modify func(p *Params)
+		want   string
+	}{
+		{
+			name:   "Trailing separator",
+			query:  "a=1&b=2&",
+			modify: func(p *Params) { p.Set("a", "9") },
+			want:   "a=9&b=2&",
+		},
+		{
+			name:   "Leading separator",
+			query:  "&a=1;b=%7e",
+			modify: func(p *Params) { p.Set("a", "9") },
+			want:   "&a=9;b=%7e",
+		},
+		{
+			name:   "Trailing run on the modified param",
+			query:  "a=1&&",
+			modify: func(p *Params) { p.Set("a", "9") },
+			want:   "a=9&&",
+		},
+		{
+			name:   "Leading run of a deleted param",
+			query:  "&&a=1;b=%7e",
+			modify: func(p *Params) { p.Delete("a") },
+			want:   "b=%7e",
+		},
+		{
+			name:   "Trailing run after an added param",
+			query:  "a=1&b=2&",
+			modify: func(p *Params) { p.Add("c", "3") },
+			want:   "a=1&b=2&c=3",
+		},
+		{
+			name:   "Sort",
+			query:  ";b=1&a=2&",
+			modify: func(p *Params) { p.Sort() },
+			want:   "a=2;b=1",
+		},
+		{
+			name:   "Unify separators",
+			query:  ";b=1;a=2;",
+			modify: func(p *Params) { UnifySeparators(p) },
+			want:   "b=1&a=2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parser{KeepRaw: true}.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("Parser.ParseQuery() error = %v", err)
+			}
+			tt.modify(&p)
+			if got := p.Encode(); got != tt.want {
+				t.Errorf("Params.Encode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,255 +1,157 @@
 package urlqm
 
 import (
-	"net/url"
 	"strings"
 )
 
 // ExtractQueryParam removes and returns the value of a parameter from the query string.
-// If the given key contains non-ASCII characters, it must be url-encoded before calling this function.
+// If the given key contains non-ASCII characters, it must be url-encoded before calling this function,
+// or [Parser.DecodeKeys] can be used instead.
 func ExtractQueryParam(query *string, key string) (value string, err error) {
-	before, after, _ := strings.Cut(*query, key+"=")
-	//if the given param wasn't found or it was without value
-	if after == "" {
-		return
-	}
-
-	var sep string
-
-	before, sep = trimParamSeparator(before)
-	buf := strings.Builder{}
-	buf.WriteString(before)
-
-	value, after = cutStringByAnySep(after, separators)
-	value, err = url.QueryUnescape(value)
-	if err != nil {
-		return
-	}
-
-	if buf.Len() > 0 && after != "" {
-		buf.WriteString(sep)
-	}
-	buf.WriteString(after)
+	return defaultParser.ExtractQueryParam(query, key)
+}
 
-	*query = buf.String()
-	return
+// LookupExtractQueryParam is like [ExtractQueryParam], but it also reports whether the parameter is present
+// in the query string, like [LookupQueryParam] does. A present parameter is removed unless its value fails to be decoded.
+// If the given key contains non-ASCII characters, it must be url-encoded before calling this function,
+// or [Parser.DecodeKeys] can be used instead.
+func LookupExtractQueryParam(query *string, key string) (value string, found bool, err error) {
+	return defaultParser.LookupExtractQueryParam(query, key)
 }
 
 // ExtractQueryParamAll removes and returns a slice of values for a parameter from the query string.
-// If the given key contains non-ASCII characters, it must be url-encoded before calling this function.
+// The returned slice is nil only if nothing was removed.
+// If the given key contains non-ASCII characters, it must be url-encoded before calling this function,
+// or [Parser.DecodeKeys] can be used instead.
 func ExtractQueryParamAll(query *string, key string) (values []string, err error) {
-	var after string = *query
-
-	buf := strings.Builder{}
-	found := false
-
-	for after != "" {
-		var before string
-		before, after, _ = strings.Cut(after, key+"=")
-		if after == "" {
-			buf.WriteString(before)
-			break
-		}
-		found = true
-		before, sep := trimParamSeparator(before)
-		buf.WriteString(before)
-
-		var value string
-		value, after = cutStringByAnySep(after, separators)
-		value, err = url.QueryUnescape(value)
-		if err != nil {
-			return nil, err
-		}
-		values = append(values, value)
-		if buf.Len() > 0 && !strings.HasPrefix(after, key) {
-			buf.WriteString(sep)
-		}
-	}
-
-	if found {
-		*query = buf.String()
-	}
-
-	return
+	return defaultParser.ExtractQueryParamAll(query, key)
 }
 
 // GetQueryParam returns the value of a parameter from the query string.
-// If the given key contains non-ASCII characters, it must be url-encoded before calling this function.
+// A valueless parameter (a bare key) is found as well, and its value is empty.
+// If the given key contains non-ASCII characters, it must be url-encoded before calling this function,
+// or [Parser.DecodeKeys] can be used instead.
 func GetQueryParam(query string, key string) (value string, err error) {
-	_, after, _ := strings.Cut(query, key+"=")
-	//if the given param wasn't found or it was without value
-	if after == "" {
-		return
-	}
+	return defaultParser.GetQueryParam(query, key)
+}
 
-	value, _ = cutStringByAnySep(after, separators)
-	value, err = url.QueryUnescape(value)
-	return
+// LookupQueryParam returns the value of a parameter from the query string,
+// and reports whether the parameter is present, so an absent parameter can be told apart from an empty one.
+// If the given key contains non-ASCII characters, it must be url-encoded before calling this function,
+// or [Parser.DecodeKeys] can be used instead.
+func LookupQueryParam(query string, key string) (value string, found bool, err error) {
+	return defaultParser.LookupQueryParam(query, key)
 }
 
 // GetQueryParamAll returns the slice of values for a parameter from the query string.
-// If the given key contains non-ASCII characters, it must be url-encoded before calling this function.
+// If the given key contains non-ASCII characters, it must be url-encoded before calling this function,
+// or [Parser.DecodeKeys] can be used instead.
 func GetQueryParamAll(query, key string) (values []string, err error) {
-
-	for query != "" {
-		_, query, _ = strings.Cut(query, key+"=")
-		if query == "" {
-			break
-		}
-		value, _ := cutStringByAnySep(query, separators)
-		if value, err = url.QueryUnescape(value); err != nil {
-			return []string{}, err
-		}
-		values = append(values, value)
-	}
-
-	return
+	return defaultParser.GetQueryParamAll(query, key)
 }
 
 // AddQueryParam adds a parameter to the query string.
 // This function accepts multiple values for a single param.
 func AddQueryParam(query *string, key string, values ...string) {
-
-	if key == "" {
-		return
-	}
-	buf := strings.Builder{}
-
-	if len(*query) > 0 {
-		buf.WriteString(*query)
-		buf.WriteByte('&')
-	}
-	writeParam(&buf, "&", key, values...)
-	*query = buf.String()
+	defaultParser.AddQueryParam(query, key, values...)
 }
 
 // SetQueryParam sets a parameter in the query string.
+// It takes place of the first parameter with the same key, and the other parameters with this key are removed.
 func SetQueryParam(query *string, key string, value string) {
-	if key == "" {
-		return
-	}
-
-	found := false
-	var after string = *query
-
-	buf := strings.Builder{}
-
-	for after != "" {
-		var before string
-		before, after, _ = strings.Cut(after, key+"=")
-
-		before, sep := trimParamSeparator(before)
-
-		buf.WriteString(before)
-		if after == "" {
-			break
-		}
-
-		_, after = cutStringByAnySep(after, separators)
-
-		if buf.Len() > 0 && after != "" {
-			buf.WriteString(sep)
-		}
-		if !found {
-			found = true
-			writeParam(&buf, sep, key, value)
-			if after != "" {
-
-				buf.WriteString(sep)
-			}
-		}
-	}
-
-	if !found {
-		if buf.Len() > 0 {
-			buf.WriteByte('&')
-		}
-		writeParam(&buf, "&", key, value)
-	}
+	defaultParser.SetQueryParam(query, key, value)
+}
 
-	*query = buf.String()
+// SetQueryFlag sets a valueless parameter (a bare key) in the query string.
+// Like [SetQueryParam], it takes place of the first parameter with the same key,
+// and the other parameters with this key are removed.
+func SetQueryFlag(query *string, key string) {
+	defaultParser.SetQueryFlag(query, key)
 }
 
 // DeleteQueryParam removes a parameter from the query string by key.
-// If the given key contains non-ASCII characters, it must be url-encoded before calling this function.
+// If the given key contains non-ASCII characters, it must be url-encoded before calling this function,
+// or [Parser.DecodeKeys] can be used instead.
 func DeleteQueryParam(query *string, key string) {
-	before, after, _ := strings.Cut(*query, key+"=")
-	//if the given param wasn't found or it was without value
-	if after == "" {
-		return
-	}
-
-	var sep string
-	before, sep = trimParamSeparator(before)
-	buf := strings.Builder{}
-	buf.WriteString(before)
-
-	_, after = cutStringByAnySep(after, separators)
-
-	if buf.Len() > 0 && after != "" {
-		buf.WriteString(sep)
-	}
-	buf.WriteString(after)
-
-	*query = buf.String()
+	defaultParser.DeleteQueryParam(query, key)
 }
 
 // DeleteQueryParamAll removes all parameters from the query string by key.
-// If the given key contains non-ASCII characters, it must be url-encoded before calling this function.
+// If the given key contains non-ASCII characters, it must be url-encoded before calling this function,
+// or [Parser.DecodeKeys] can be used instead.
 func DeleteQueryParamAll(query *string, key string) {
-	var after string = *query
+	defaultParser.DeleteQueryParamAll(query, key)
+}
 
-	buf := strings.Builder{}
-	found := false
+// DeleteQueryParams removes all parameters with any of the given keys from the query string, in a single pass.
+// If the given keys contain non-ASCII characters, they must be url-encoded before calling this function,
+// or [Parser.DecodeKeys] can be used instead.
+func DeleteQueryParams(query *string, keys ...string) {
+	defaultParser.DeleteQueryParams(query, keys...)
+}
 
-	for after != "" {
-		var before string
-		before, after, _ = strings.Cut(after, key+"=")
-		if after == "" {
-			buf.WriteString(before)
-			break
-		}
-		found = true
-		before, sep := trimParamSeparator(before)
+// KeepQueryParams removes all parameters from the query string except the ones with the given keys,
+// in a single pass. Without keys, it removes all parameters.
+// If the given keys contain non-ASCII characters, they must be url-encoded before calling this function,
+// or [Parser.DecodeKeys] can be used instead.
+func KeepQueryParams(query *string, keys ...string) {
+	defaultParser.KeepQueryParams(query, keys...)
+}
 
-		buf.WriteString(before)
-		_, after = cutStringByAnySep(after, separators)
+// DeleteQueryParamsFunc removes all parameters whose keys match from the query string, in a single pass.
+// The keys are passed to match as they are in the query string, still escaped,
+// or decoded if [Parser.DecodeKeys] is set. The rules, like [PrefixRule], [GlobRule] and [RegexpRule], are matchers too:
+//
+//	DeleteQueryParamsFunc(&u.RawQuery, GlobRule("utm_*", "*_id").Match)
+func DeleteQueryParamsFunc(query *string, match func(key string) bool) {
+	defaultParser.DeleteQueryParamsFunc(query, match)
+}
 
-		if buf.Len() > 0 && !strings.HasPrefix(after, key) {
-			buf.WriteString(sep)
-		}
+// KeepQueryParamsFunc removes all parameters whose keys don't match from the query string, in a single pass.
+// See [DeleteQueryParamsFunc].
+func KeepQueryParamsFunc(query *string, match func(key string) bool) {
+	defaultParser.KeepQueryParamsFunc(query, match)
+}
 
-	}
+// HasQueryParam returns true if the query string contains a parameter with the given key,
+// either with a value or as a bare key.
+// If the given key contains non-ASCII characters, it must be url-encoded before calling this function,
+// or [Parser.DecodeKeys] can be used instead.
+func HasQueryParam(query string, key string) bool {
+	return defaultParser.HasQueryParam(query, key)
+}
 
-	if found {
-		*query = buf.String()
-	}
+// HasQueryFlag returns true if the query string contains a valueless parameter (a bare key) with the given key.
+// If the given key contains non-ASCII characters, it must be url-encoded before calling this function,
+// or [Parser.DecodeKeys] can be used instead.
+func HasQueryFlag(query string, key string) bool {
+	return defaultParser.HasQueryFlag(query, key)
 }
 
-// HasQueryParam returns true if the query string contains a parameter with the given key.
-// If the given key contains non-ASCII characters, it must be url-encoded before calling this function.
-func HasQueryParam(query string, key string) bool {
-	return strings.Contains(query, key+"=")
+// DeleteQueryFlag removes all valueless parameters (bare keys) from the query string by key.
+// Parameters with the same key and a value are kept.
+// If the given key contains non-ASCII characters, it must be url-encoded before calling this function,
+// or [Parser.DecodeKeys] can be used instead.
+func DeleteQueryFlag(query *string, key string) {
+	defaultParser.DeleteQueryFlag(query, key)
 }
 
-func writeParam(buf *strings.Builder, sep, key string, values ...string) {
-	buf.WriteString(url.QueryEscape(key))
+func writeParam(buf *strings.Builder, e Escaper, sep, key string, values ...string) {
+	buf.WriteString(e.Escape(key))
 	buf.WriteByte('=')
 	if len(values) == 0 {
 		return
 	}
 
-	buf.WriteString(url.QueryEscape(values[0]))
+	buf.WriteString(e.Escape(values[0]))
 
 	if len(values) > 1 {
 
 		for _, value := range values[1:] {
 			buf.WriteString(sep)
-			buf.WriteString(url.QueryEscape(key))
+			buf.WriteString(e.Escape(key))
 			buf.WriteByte('=')
-			buf.WriteString(url.QueryEscape(value))
+			buf.WriteString(e.Escape(value))
 		}
 	}
 }
-
-// TODO: github actions
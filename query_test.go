@@ -2,6 +2,7 @@ package urlqm
 
 import (
 	"reflect"
+	"regexp"
 	"testing"
 )
 
@@ -421,6 +422,21 @@ func TestDeleteQueryParam(t *testing.T) {
 			args:      args{query: `q=%22daily+news%22&theme=dark`, key: "q"},
 			wantQuery: `theme=dark`,
 		},
+		{
+			name:      "Empty segments collapse",
+			args:      args{query: "a=1&&b=2&&c=3", key: "b"},
+			wantQuery: "a=1&c=3",
+		},
+		{
+			name:      "Empty segments at the end",
+			args:      args{query: "a=1&&b=2&", key: "b"},
+			wantQuery: "a=1",
+		},
+		{
+			name:      "Empty segments at the start",
+			args:      args{query: "&&b=2&&c=3", key: "b"},
+			wantQuery: "c=3",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -514,3 +530,514 @@ func TestHasQueryParam(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryParamKeyBoundaries(t *testing.T) {
+	// Keys must match only as a whole and only at the beginning of a param,
+	// so neither a longer key with the same suffix nor a value containing "key=" is matched.
+	tests := []struct {
+		name           string
+		query          string
+		key            string
+		wantValue      string
+		wantValues     []string
+		wantHas        bool
+		wantDeleted    string
+		wantDeletedAll string
+		wantSet        string
+	}{
+		{
+			name:           "Key is a suffix of another key",
+			query:          "uid=5&id=7",
+			key:            "id",
+			wantValue:      "7",
+			wantValues:     []string{"7"},
+			wantHas:        true,
+			wantDeleted:    "uid=5",
+			wantDeletedAll: "uid=5",
+			wantSet:        "uid=5&id=X",
+		},
+		{
+			name:           "Only a longer key is present",
+			query:          "uid=5&pid=6",
+			key:            "id",
+			wantValue:      "",
+			wantValues:     nil,
+			wantHas:        false,
+			wantDeleted:    "uid=5&pid=6",
+			wantDeletedAll: "uid=5&pid=6",
+			wantSet:        "uid=5&pid=6&id=X",
+		},
+		{
+			name:           "Key is a prefix of another key",
+			query:          "idx=1&id=2",
+			key:            "id",
+			wantValue:      "2",
+			wantValues:     []string{"2"},
+			wantHas:        true,
+			wantDeleted:    "idx=1",
+			wantDeletedAll: "idx=1",
+			wantSet:        "idx=1&id=X",
+		},
+		{
+			name:           "Value contains key=",
+			query:          "q=id=3&id=4",
+			key:            "id",
+			wantValue:      "4",
+			wantValues:     []string{"4"},
+			wantHas:        true,
+			wantDeleted:    "q=id=3",
+			wantDeletedAll: "q=id=3",
+			wantSet:        "q=id=3&id=X",
+		},
+		{
+			name:           "Only a value contains key=",
+			query:          "q=id=3&r=xid=5",
+			key:            "id",
+			wantValue:      "",
+			wantValues:     nil,
+			wantHas:        false,
+			wantDeleted:    "q=id=3&r=xid=5",
+			wantDeletedAll: "q=id=3&r=xid=5",
+			wantSet:        "q=id=3&r=xid=5&id=X",
+		},
+		{
+			name:           "Mixed lookalikes with several matches",
+			query:          "uid=1;id=2&q=id=3&id=4&ids=5",
+			key:            "id",
+			wantValue:      "2",
+			wantValues:     []string{"2", "4"},
+			wantHas:        true,
+			wantDeleted:    "uid=1;q=id=3&id=4&ids=5",
+			wantDeletedAll: "uid=1;q=id=3&ids=5",
+			wantSet:        "uid=1;id=X&q=id=3&ids=5",
+		},
+		{
+			name:           "Last param without value",
+			query:          "uid=1&id=",
+			key:            "id",
+			wantValue:      "",
+			wantValues:     []string{""},
+			wantHas:        true,
+			wantDeleted:    "uid=1",
+			wantDeletedAll: "uid=1",
+			wantSet:        "uid=1&id=X",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotValue, err := GetQueryParam(tt.query, tt.key)
+			if err != nil {
+				t.Errorf("GetQueryParam() error = %v", err)
+			}
+			if gotValue != tt.wantValue {
+				t.Errorf("GetQueryParam() = %v, want %v", gotValue, tt.wantValue)
+			}
+
+			gotValues, err := GetQueryParamAll(tt.query, tt.key)
+			if err != nil {
+				t.Errorf("GetQueryParamAll() error = %v", err)
+			}
+			if !reflect.DeepEqual(gotValues, tt.wantValues) {
+				t.Errorf("GetQueryParamAll() = %v, want %v", gotValues, tt.wantValues)
+			}
+
+			if got := HasQueryParam(tt.query, tt.key); got != tt.wantHas {
+				t.Errorf("HasQueryParam() = %v, want %v", got, tt.wantHas)
+			}
+
+			query := tt.query
+			gotValue, err = ExtractQueryParam(&query, tt.key)
+			if err != nil {
+				t.Errorf("ExtractQueryParam() error = %v", err)
+			}
+			if gotValue != tt.wantValue {
+				t.Errorf("ExtractQueryParam() = %v, want %v", gotValue, tt.wantValue)
+			}
+			if query != tt.wantDeleted {
+				t.Errorf("ExtractQueryParam() query = %v, want %v", query, tt.wantDeleted)
+			}
+
+			query = tt.query
+			gotValues, err = ExtractQueryParamAll(&query, tt.key)
+			if err != nil {
+				t.Errorf("ExtractQueryParamAll() error = %v", err)
+			}
+			if !reflect.DeepEqual(gotValues, tt.wantValues) {
+				t.Errorf("ExtractQueryParamAll() = %v, want %v", gotValues, tt.wantValues)
+			}
+			if query != tt.wantDeletedAll {
+				t.Errorf("ExtractQueryParamAll() query = %v, want %v", query, tt.wantDeletedAll)
+			}
+
+			query = tt.query
+			DeleteQueryParam(&query, tt.key)
+			if query != tt.wantDeleted {
+				t.Errorf("DeleteQueryParam() query = %v, want %v", query, tt.wantDeleted)
+			}
+
+			query = tt.query
+			DeleteQueryParamAll(&query, tt.key)
+			if query != tt.wantDeletedAll {
+				t.Errorf("DeleteQueryParamAll() query = %v, want %v", query, tt.wantDeletedAll)
+			}
+
+			query = tt.query
+			SetQueryParam(&query, tt.key, "X")
+			if query != tt.wantSet {
+				t.Errorf("SetQueryParam() query = %v, want %v", query, tt.wantSet)
+			}
+		})
+	}
+}
+
+func TestQueryParamFlags(t *testing.T) {
+	// A bare key is a param with an empty value for the common functions.
+	query := "debug&a=1;verbose"
+
+	if value, err := GetQueryParam(query, "debug"); err != nil || value != "" || !HasQueryParam(query, "debug") {
+		t.Errorf("GetQueryParam() = %q, %v; want an empty value of the present key", value, err)
+	}
+	if values, _ := GetQueryParamAll(query, "verbose"); !reflect.DeepEqual(values, []string{""}) {
+		t.Errorf("GetQueryParamAll() = %v, want %v", values, []string{""})
+	}
+	q := query
+	DeleteQueryParam(&q, "debug")
+	if q != "a=1;verbose" {
+		t.Errorf("DeleteQueryParam() query = %v, want %v", q, "a=1;verbose")
+	}
+	q = query
+	SetQueryParam(&q, "verbose", "2")
+	if q != "debug&a=1;verbose=2" {
+		t.Errorf("SetQueryParam() query = %v, want %v", q, "debug&a=1;verbose=2")
+	}
+}
+
+func TestHasQueryFlag(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		key   string
+		want  bool
+	}{
+		{name: "Single", query: "debug", key: "debug", want: true},
+		{name: "First", query: "debug&a=1", key: "debug", want: true},
+		{name: "Last", query: "a=1;debug", key: "debug", want: true},
+		{name: "With value", query: "a=1&debug=1", key: "debug", want: false},
+		{name: "With empty value", query: "a=1&debug=", key: "debug", want: false},
+		{name: "After the same key with value", query: "debug=1&debug", key: "debug", want: true},
+		{name: "Longer key", query: "a=1&debugger", key: "debug", want: false},
+		{name: "In value", query: "a=debug&b", key: "debug", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasQueryFlag(tt.query, tt.key); got != tt.want {
+				t.Errorf("HasQueryFlag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetQueryFlag(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		key       string
+		wantQuery string
+	}{
+		{name: "Empty query no key", query: "", key: "", wantQuery: ""},
+		{name: "Empty query", query: "", key: "debug", wantQuery: "debug"},
+		{name: "New key", query: "a=1&b=2", key: "debug", wantQuery: "a=1&b=2&debug"},
+		{name: "Existing flag", query: "a=1&debug&b=2", key: "debug", wantQuery: "a=1&debug&b=2"},
+		{name: "Existing params", query: "debug=1&a=1&debug=2&b=2", key: "debug", wantQuery: "debug&a=1&b=2"},
+		{name: "Encoded key", query: "a=1", key: "ключ", wantQuery: "a=1&%D0%BA%D0%BB%D1%8E%D1%87"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			SetQueryFlag(&query, tt.key)
+			if query != tt.wantQuery {
+				t.Errorf("SetQueryFlag() query = %v, want %v", query, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestDeleteQueryFlag(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		key       string
+		wantQuery string
+	}{
+		{name: "Not found", query: "a=1&b=2", key: "debug", wantQuery: "a=1&b=2"},
+		{name: "Single", query: "debug", key: "debug", wantQuery: ""},
+		{name: "First", query: "debug&a=1", key: "debug", wantQuery: "a=1"},
+		{name: "Last", query: "a=1;debug", key: "debug", wantQuery: "a=1"},
+		{name: "Many", query: "debug&a=1&debug;b=2&debug", key: "debug", wantQuery: "a=1&b=2"},
+		{name: "Keeps params with value", query: "debug=1&a=1&debug", key: "debug", wantQuery: "debug=1&a=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			DeleteQueryFlag(&query, tt.key)
+			if query != tt.wantQuery {
+				t.Errorf("DeleteQueryFlag() query = %v, want %v", query, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestLookupQueryParam(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		key       string
+		wantValue string
+		wantFound bool
+		wantErr   bool
+	}{
+		{name: "Not found", query: "a=1&b=2", key: "c", wantValue: "", wantFound: false},
+		{name: "Found", query: "a=1&b=2", key: "b", wantValue: "2", wantFound: true},
+		{name: "Found empty", query: "a=1&b=&c=3", key: "b", wantValue: "", wantFound: true},
+		{name: "Found flag", query: "a=1&b", key: "b", wantValue: "", wantFound: true},
+		{name: "bad encoding", query: "q=%-daily&b=2", key: "q", wantValue: "", wantFound: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotValue, gotFound, err := LookupQueryParam(tt.query, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LookupQueryParam() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotValue != tt.wantValue {
+				t.Errorf("LookupQueryParam() value = %v, want %v", gotValue, tt.wantValue)
+			}
+			if gotFound != tt.wantFound {
+				t.Errorf("LookupQueryParam() found = %v, want %v", gotFound, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestLookupExtractQueryParam(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		key       string
+		wantValue string
+		wantFound bool
+		wantErr   bool
+		wantQuery string
+	}{
+		{name: "Not found", query: "a=1&b=2", key: "c", wantFound: false, wantQuery: "a=1&b=2"},
+		{name: "Found", query: "a=1&b=2", key: "b", wantValue: "2", wantFound: true, wantQuery: "a=1"},
+		{name: "Found empty", query: "a=1&b=&c=3", key: "b", wantValue: "", wantFound: true, wantQuery: "a=1&c=3"},
+		{name: "Found flag", query: "b&a=1", key: "b", wantValue: "", wantFound: true, wantQuery: "a=1"},
+		{name: "bad encoding", query: "q=%-daily&b=2", key: "q", wantFound: true, wantErr: true, wantQuery: "q=%-daily&b=2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			gotValue, gotFound, err := LookupExtractQueryParam(&query, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LookupExtractQueryParam() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotValue != tt.wantValue {
+				t.Errorf("LookupExtractQueryParam() value = %v, want %v", gotValue, tt.wantValue)
+			}
+			if gotFound != tt.wantFound {
+				t.Errorf("LookupExtractQueryParam() found = %v, want %v", gotFound, tt.wantFound)
+			}
+			if query != tt.wantQuery {
+				t.Errorf("LookupExtractQueryParam() query = %v, want %v", query, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestDeleteQueryParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser Parser
+		query  string
+		keys   []string
+		want   string
+	}{
+		{
+			name:  "No keys",
+			query: "a=1&b=2",
+			want:  "a=1&b=2",
+		},
+		{
+			name:  "Not found",
+			query: "a=1&b=2",
+			keys:  []string{"c", "d"},
+			want:  "a=1&b=2",
+		},
+		{
+			name:  "Found many",
+			query: "a=1&b=2&c=3&b=4&d&a=5&e=6",
+			keys:  []string{"a", "b", "d"},
+			want:  "c=3&e=6",
+		},
+		{
+			name:  "All",
+			query: "a=1&b=2&a=3",
+			keys:  []string{"a", "b"},
+			want:  "",
+		},
+		{
+			name:  "Empty segments collapse",
+			query: "a=1&&b=2&&c=3&;d=4;&",
+			keys:  []string{"b", "d"},
+			want:  "a=1&c=3",
+		},
+		{
+			name:  "Adjacent removed params",
+			query: "a=1&&b=2&&c=3&&d=4",
+			keys:  []string{"b", "c"},
+			want:  "a=1&d=4",
+		},
+		{
+			name:  "Encoded keys",
+			query: "%D0%BA%D0%BB%D1%8E%D1%87=1&q=go",
+			keys:  []string{"%D0%BA%D0%BB%D1%8E%D1%87"},
+			want:  "q=go",
+		},
+		{
+			name:   "Decoded keys",
+			parser: Parser{DecodeKeys: true},
+			query:  "%D0%BA%D0%BB%D1%8E%D1%87=1&q=go&a%5Fb=2",
+			keys:   []string{"ключ", "a_b"},
+			want:   "q=go",
+		},
+		{
+			name:   "Custom separators",
+			parser: Parser{Separators: ";"},
+			query:  "a=1;b=2&c=3;a=4",
+			keys:   []string{"a"},
+			want:   "b=2&c=3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			tt.parser.DeleteQueryParams(&query, tt.keys...)
+			if query != tt.want {
+				t.Errorf("Parser.DeleteQueryParams() = %q, want %q", query, tt.want)
+			}
+		})
+	}
+
+	query := "a=1&b=2&c=3&a=4"
+	DeleteQueryParams(&query, "a", "c")
+	if query != "b=2" {
+		t.Errorf("DeleteQueryParams() = %q, want %q", query, "b=2")
+	}
+}
+
+func TestKeepQueryParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser Parser
+		query  string
+		keys   []string
+		want   string
+	}{
+		{
+			name:  "No keys",
+			query: "a=1&b=2",
+			want:  "",
+		},
+		{
+			name:  "Kept",
+			query: "utm_source=x&q=go&ref=home&page=2&q=rust&debug",
+			keys:  []string{"q", "page", "debug"},
+			want:  "q=go&page=2&q=rust&debug",
+		},
+		{
+			name:  "Nothing kept",
+			query: "a=1&b=2",
+			keys:  []string{"c"},
+			want:  "",
+		},
+		{
+			name:   "Decoded keys",
+			parser: Parser{DecodeKeys: true},
+			query:  "%D0%BA%D0%BB%D1%8E%D1%87=1&q=go",
+			keys:   []string{"ключ"},
+			want:   "%D0%BA%D0%BB%D1%8E%D1%87=1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			tt.parser.KeepQueryParams(&query, tt.keys...)
+			if query != tt.want {
+				t.Errorf("Parser.KeepQueryParams() = %q, want %q", query, tt.want)
+			}
+		})
+	}
+
+	query := "a=1&b=2&c=3&a=4"
+	KeepQueryParams(&query, "a")
+	if query != "a=1&a=4" {
+		t.Errorf("KeepQueryParams() = %q, want %q", query, "a=1&a=4")
+	}
+}
+
+func TestDeleteQueryParamsFunc(t *testing.T) {
+	query := "utm_source=x&q=go&session_id=1&user_id=2&page=2&ref=home&v1=a&v10=b"
+	tests := []struct {
+		name       string
+		match      func(key string) bool
+		wantDelete string
+		wantKeep   string
+	}{
+		{
+			name:       "Prefix",
+			match:      PrefixRule("utm_", "v1").Match,
+			wantDelete: "q=go&session_id=1&user_id=2&page=2&ref=home",
+			wantKeep:   "utm_source=x&v1=a&v10=b",
+		},
+		{
+			name:       "Glob",
+			match:      GlobRule("utm_*", "*_id", "v?").Match,
+			wantDelete: "q=go&page=2&ref=home&v10=b",
+			wantKeep:   "utm_source=x&session_id=1&user_id=2&v1=a",
+		},
+		{
+			name:       "Regexp",
+			match:      RegexpRule(regexp.MustCompile(`^(q|page)$`)).Match,
+			wantDelete: "utm_source=x&session_id=1&user_id=2&ref=home&v1=a&v10=b",
+			wantKeep:   "q=go&page=2",
+		},
+		{
+			name:       "Func",
+			match:      func(key string) bool { return len(key) > 3 },
+			wantDelete: "q=go&ref=home&v1=a&v10=b",
+			wantKeep:   "utm_source=x&session_id=1&user_id=2&page=2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := query
+			DeleteQueryParamsFunc(&got, tt.match)
+			if got != tt.wantDelete {
+				t.Errorf("DeleteQueryParamsFunc() = %q, want %q", got, tt.wantDelete)
+			}
+			got = query
+			KeepQueryParamsFunc(&got, tt.match)
+			if got != tt.wantKeep {
+				t.Errorf("KeepQueryParamsFunc() = %q, want %q", got, tt.wantKeep)
+			}
+		})
+	}
+
+	got := "a=1;utm_source=x;b=2"
+	Parser{Separators: ";"}.DeleteQueryParamsFunc(&got, GlobRule("utm_*").Match)
+	if got != "a=1;b=2" {
+		t.Errorf("Parser.DeleteQueryParamsFunc() = %q, want %q", got, "a=1;b=2")
+	}
+}
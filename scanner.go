@@ -0,0 +1,263 @@
+package urlqm
+
+import (
+	"net/url"
+	"strings"
+)
+
+// QueryScanner walks through the params of a raw query string once, without building a []Param.
+// Keys and values are decoded only on demand, either into a new string with [QueryScanner.Key]
+// and [QueryScanner.Value], or into a caller-supplied buffer with [QueryScanner.AppendKey]
+// and [QueryScanner.AppendValue], so scanning a query doesn't allocate at all.
+//
+// It splits a query string exactly like [Parser.ParseParams] does, since the latter is built on top of it.
+// Empty segments between separators are skipped.
+//
+//	s := NewQueryScanner(u.RawQuery)
+//	for s.Next() {
+//		if s.RawKey() == "uuid" {
+//			fmt.Println(s.Value())
+//		}
+//	}
+//	if err := s.Err(); err != nil {
+//		// handle the error
+//	}
+type QueryScanner struct {
+	query  string
+	parser Parser
+
+	// pos is the offset of the next segment, prevEnd is the end of the current param.
+	pos, prevEnd int
+	// start and end are the bounds of the current param, eq is the offset of its '=' or -1.
+	start, end, eq int
+	// sepStart is the offset of the separators before the current param.
+	sepStart int
+	index    int
+	err      error
+}
+
+// NewQueryScanner returns a [QueryScanner] that splits the query with the default [Parser] rules.
+func NewQueryScanner(query string) *QueryScanner {
+	return defaultParser.NewScanner(query)
+}
+
+// NewScanner returns a [QueryScanner] that splits and decodes the query according to the parser options.
+func (p Parser) NewScanner(query string) *QueryScanner {
+	return &QueryScanner{query: query, parser: p, index: -1}
+}
+
+// Next advances the scanner to the next param, which will then be available through the other methods.
+// It returns false when there are no more params, or when a strict [Parser] met a malformed param.
+func (s *QueryScanner) Next() bool {
+	if s.err != nil && s.parser.Strict {
+		return false
+	}
+	seps := s.parser.separators()
+	for s.pos < len(s.query) {
+		start := s.pos
+		end := len(s.query)
+		if i := strings.IndexAny(s.query[start:], seps); i >= 0 {
+			end = start + i
+		}
+		s.pos = end + 1
+		if start == end {
+			continue
+		}
+
+		s.sepStart, s.start, s.end = s.prevEnd, start, end
+		s.prevEnd = end
+		s.index++
+		s.eq = -1
+		if i := strings.IndexByte(s.query[start:end], '='); i >= 0 {
+			s.eq = start + i
+		}
+		if s.parser.Strict && s.eq == start {
+			s.setErr(s.newError(PartKey, ErrEmptyKey))
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// RawKey returns the key of the current param as it is in the query string.
+func (s *QueryScanner) RawKey() string {
+	if s.eq < 0 {
+		return s.query[s.start:s.end]
+	}
+	return s.query[s.start:s.eq]
+}
+
+// RawValue returns the value of the current param as it is in the query string.
+func (s *QueryScanner) RawValue() string {
+	if s.eq < 0 {
+		return ""
+	}
+	return s.query[s.eq+1 : s.end]
+}
+
+// Flag reports whether the current param is valueless (a bare key without '=').
+func (s *QueryScanner) Flag() bool {
+	return s.eq < 0
+}
+
+// Offset returns the byte offset of the current param in the query string.
+func (s *QueryScanner) Offset() int {
+	return s.start
+}
+
+// Index returns the position of the current param among the params of the query string, starting from 0.
+func (s *QueryScanner) Index() int {
+	return s.index
+}
+
+// Key returns the decoded key of the current param.
+// If the key can't be decoded, it returns the raw key and the error is available through [QueryScanner.Err].
+// It allocates only if the key contains escape sequences.
+func (s *QueryScanner) Key() string {
+	key, err := s.decodeKey()
+	if err != nil {
+		s.setErr(err)
+	}
+	return key
+}
+
+// Value returns the decoded value of the current param.
+// If the value can't be decoded, it returns the raw value and the error is available through [QueryScanner.Err].
+// It allocates only if the value contains escape sequences.
+func (s *QueryScanner) Value() string {
+	value, err := s.decodeValue()
+	if err != nil {
+		s.setErr(err)
+	}
+	return value
+}
+
+// AppendKey appends the decoded key of the current param to dst and returns the extended buffer.
+// If the key can't be decoded, dst is returned unchanged along with a [*ParseError].
+func (s *QueryScanner) AppendKey(dst []byte) ([]byte, error) {
+	return s.appendUnescaped(dst, s.RawKey(), PartKey)
+}
+
+// AppendValue appends the decoded value of the current param to dst and returns the extended buffer.
+// If the value can't be decoded, dst is returned unchanged along with a [*ParseError].
+func (s *QueryScanner) AppendValue(dst []byte) ([]byte, error) {
+	return s.appendUnescaped(dst, s.RawValue(), PartValue)
+}
+
+// Err returns the first error met by the scanner: a key or a value that failed to be decoded by
+// [QueryScanner.Key] or [QueryScanner.Value], or a malformed param which stopped a strict scanner.
+// The error is a [*ParseError].
+func (s *QueryScanner) Err() error {
+	return s.err
+}
+
+// sep returns the separators between the previous param and the current one.
+func (s *QueryScanner) sep() string {
+	return s.query[s.sepStart:s.start]
+}
+
+// decodeKey returns the decoded key of the current param,
+// or the raw key and a [*ParseError] if the key can't be decoded.
+func (s *QueryScanner) decodeKey() (string, *ParseError) {
+	raw := s.RawKey()
+	key, err := unescape(raw, !s.parser.KeepPlus)
+	if err != nil {
+		return raw, s.newError(PartKey, err)
+	}
+	return key, nil
+}
+
+// decodeValue returns the decoded value of the current param,
+// or the raw value and a [*ParseError] if the value can't be decoded.
+func (s *QueryScanner) decodeValue() (string, *ParseError) {
+	raw := s.RawValue()
+	value, err := unescape(raw, !s.parser.KeepPlus)
+	if err != nil {
+		return raw, s.newError(PartValue, err)
+	}
+	return value, nil
+}
+
+func (s *QueryScanner) appendUnescaped(dst []byte, raw string, part ParamPart) ([]byte, error) {
+	n := len(dst)
+	dst, err := appendUnescape(dst, raw, !s.parser.KeepPlus)
+	if err != nil {
+		return dst[:n], s.newError(part, err)
+	}
+	return dst, nil
+}
+
+func (s *QueryScanner) newError(part ParamPart, err error) *ParseError {
+	return &ParseError{
+		Offset: s.start, Index: s.index,
+		Key: s.RawKey(), Value: s.RawValue(), Part: part, Err: err,
+	}
+}
+
+func (s *QueryScanner) setErr(err *ParseError) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// unescape decodes an escaped key or value. If plusAsSpace is true, '+' is decoded as a space.
+// It returns s itself if there is nothing to decode, and the same errors as `url.QueryUnescape`.
+func unescape(s string, plusAsSpace bool) (string, error) {
+	if strings.IndexByte(s, '%') < 0 && (!plusAsSpace || strings.IndexByte(s, '+') < 0) {
+		return s, nil
+	}
+	if err := validateEscapes(s); err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	buf.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '+' && plusAsSpace:
+			c = ' '
+		case c == '%':
+			c = unhex(s[i+1])<<4 | unhex(s[i+2])
+			i += 2
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String(), nil
+}
+
+// validateEscapes returns a `url.EscapeError` for the first invalid escape sequence in s.
+func validateEscapes(s string) error {
+	for i := strings.IndexByte(s, '%'); i >= 0; i = strings.IndexByte(s, '%') {
+		if i+2 >= len(s) || !ishex(s[i+1]) || !ishex(s[i+2]) {
+			s = s[i:]
+			if len(s) > 3 {
+				s = s[:3]
+			}
+			return url.EscapeError(s)
+		}
+		s = s[i+3:]
+	}
+	return nil
+}
+
+// appendUnescape appends decoded s to dst. If plusAsSpace is true, '+' is decoded as a space.
+// On failure it returns a `url.EscapeError`, like `url.QueryUnescape` does.
+func appendUnescape(dst []byte, s string, plusAsSpace bool) ([]byte, error) {
+	if err := validateEscapes(s); err != nil {
+		return dst, err
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '+' && plusAsSpace:
+			c = ' '
+		case c == '%':
+			c = unhex(s[i+1])<<4 | unhex(s[i+2])
+			i += 2
+		}
+		dst = append(dst, c)
+	}
+	return dst, nil
+}
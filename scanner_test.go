@@ -0,0 +1,160 @@
+package urlqm
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestQueryScanner(t *testing.T) {
+	type scanned struct {
+		RawKey, RawValue string
+		Key, Value       string
+		Flag             bool
+		Offset, Index    int
+	}
+	tests := []struct {
+		name    string
+		parser  Parser
+		query   string
+		want    []scanned
+		wantErr error
+	}{
+		{
+			name:  "Empty",
+			query: "",
+			want:  nil,
+		},
+		{
+			name:  "Mixed separators and empty segments",
+			query: "&a=1;;b=x+y&debug&",
+			want: []scanned{
+				{RawKey: "a", RawValue: "1", Key: "a", Value: "1", Offset: 1, Index: 0},
+				{RawKey: "b", RawValue: "x+y", Key: "b", Value: "x y", Offset: 6, Index: 1},
+				{RawKey: "debug", Key: "debug", Flag: true, Offset: 12, Index: 2},
+			},
+		},
+		{
+			name:   "Custom separators and plus",
+			parser: Parser{Separators: "&", KeepPlus: true},
+			query:  "q=a;b+c&k%20=",
+			want: []scanned{
+				{RawKey: "q", RawValue: "a;b+c", Key: "q", Value: "a;b+c", Offset: 0, Index: 0},
+				{RawKey: "k%20", RawValue: "", Key: "k ", Value: "", Offset: 8, Index: 1},
+			},
+		},
+		{
+			name:  "Bad escape",
+			query: "a=1&b=90%&c%zz=3",
+			want: []scanned{
+				{RawKey: "a", RawValue: "1", Key: "a", Value: "1", Offset: 0, Index: 0},
+				{RawKey: "b", RawValue: "90%", Key: "b", Value: "90%", Offset: 4, Index: 1},
+				{RawKey: "c%zz", RawValue: "3", Key: "c%zz", Value: "3", Offset: 10, Index: 2},
+			},
+			wantErr: &ParseError{Offset: 4, Index: 1, Key: "b", Value: "90%", Part: PartValue, Err: url.EscapeError("%")},
+		},
+		{
+			name:   "Strict stops on empty key",
+			parser: Parser{Strict: true},
+			query:  "a=1&=2&c=3",
+			want: []scanned{
+				{RawKey: "a", RawValue: "1", Key: "a", Value: "1", Offset: 0, Index: 0},
+			},
+			wantErr: &ParseError{Offset: 4, Index: 1, Key: "", Value: "2", Part: PartKey, Err: ErrEmptyKey},
+		},
+		{
+			name:   "Strict stops on bad escape",
+			parser: Parser{Strict: true},
+			query:  "a=%&c=3",
+			want: []scanned{
+				{RawKey: "a", RawValue: "%", Key: "a", Value: "%", Offset: 0, Index: 0},
+			},
+			wantErr: &ParseError{Offset: 0, Index: 0, Key: "a", Value: "%", Part: PartValue, Err: url.EscapeError("%")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []scanned
+			s := tt.parser.NewScanner(tt.query)
+			for s.Next() {
+				got = append(got, scanned{
+					RawKey: s.RawKey(), RawValue: s.RawValue(),
+					Key: s.Key(), Value: s.Value(),
+					Flag: s.Flag(), Offset: s.Offset(), Index: s.Index(),
+				})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryScanner = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(s.Err(), tt.wantErr) {
+				t.Errorf("QueryScanner.Err() = %v, want %v", s.Err(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQueryScanner_Append(t *testing.T) {
+	s := NewQueryScanner("k%C3%A9y=v%20a+l&bad=%zz")
+	buf := make([]byte, 0, 32)
+
+	if !s.Next() {
+		t.Fatal("QueryScanner.Next() = false, want true")
+	}
+	buf, err := s.AppendKey(buf)
+	if err != nil || string(buf) != "kéy" {
+		t.Errorf("QueryScanner.AppendKey() = %q, %v, want %q", buf, err, "kéy")
+	}
+	buf, err = s.AppendValue(buf[:0])
+	if err != nil || string(buf) != "v a l" {
+		t.Errorf("QueryScanner.AppendValue() = %q, %v, want %q", buf, err, "v a l")
+	}
+
+	if !s.Next() {
+		t.Fatal("QueryScanner.Next() = false, want true")
+	}
+	buf, err = s.AppendValue(buf[:0])
+	var e url.EscapeError
+	if !errors.As(err, &e) || len(buf) != 0 {
+		t.Errorf("QueryScanner.AppendValue() = %q, %v, want an escape error", buf, err)
+	}
+	if s.Err() != nil {
+		t.Errorf("QueryScanner.Err() = %v, want nil", s.Err())
+	}
+}
+
+func TestQueryScannerMatchesParseParams(t *testing.T) {
+	queries := []string{
+		"a=1&b=2",
+		";a=1;;b=&c&&",
+		"q=100%+truth&k%20=x+y&=v&flag",
+		"a==1&b=%41%42&c=%zz",
+	}
+	for _, query := range queries {
+		params, _ := ParseParams(query)
+		var scanned []Param
+		s := NewQueryScanner(query)
+		for s.Next() {
+			scanned = append(scanned, Param{Key: s.Key(), Value: s.Value(), Flag: s.Flag()})
+		}
+		if !reflect.DeepEqual(scanned, params) {
+			t.Errorf("QueryScanner(%q) = %v, ParseParams() = %v", query, scanned, params)
+		}
+	}
+}
+
+func TestUnescape(t *testing.T) {
+	tests := []string{"", "abc", "a+b", "a%20b", "%D0%BA%d0%bb", "100%", "%zz", "%4", "a%2"}
+	for _, s := range tests {
+		for _, plus := range []bool{true, false} {
+			want, wantErr := url.QueryUnescape(s)
+			if !plus {
+				want, wantErr = url.PathUnescape(s)
+			}
+			got, err := unescape(s, plus)
+			if got != want || !reflect.DeepEqual(err, wantErr) {
+				t.Errorf("unescape(%q, %v) = %q, %v, want %q, %v", s, plus, got, err, want, wantErr)
+			}
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package urlqm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+// Schema declares the params that a query string may have, and the values they may take.
+//
+//	var searchSchema = &Schema{
+//		Params: []ParamSchema{
+//			{Key: "q", Required: true, MaxCount: 1},
+//			{Key: "page", MaxCount: 1, Range: &NumberRange{Min: 1, Max: 1000}},
+//			{Key: "sort", Enum: []string{"date", "name"}},
+//			{Key: "tag", MaxCount: 5, Pattern: regexp.MustCompile(`^[a-z]+$`)},
+//			{Key: "cursor"},
+//		},
+//		RejectUnknown: true,
+//		Exclusive:     [][]string{{"page", "cursor"}},
+//	}
+//
+// A Schema must not be modified while it is in use.
+type Schema struct {
+	// Params are the declared params. If a key is declared more than once, the first declaration is used.
+	Params []ParamSchema
+	// RejectUnknown makes the params that are not declared violations, unless AllowKeys matches their keys.
+	RejectUnknown bool
+	// AllowKeys allows undeclared params, like `utm_*`, when RejectUnknown is set.
+	AllowKeys Rule
+	// Exclusive are the groups of keys that must not appear together, only one key of a group may be present.
+	Exclusive [][]string
+}
+
+// ParamSchema declares a param of a [Schema]. The checks of the value apply to every occurrence of the param,
+// a valueless param (a flag) has an empty value.
+type ParamSchema struct {
+	Key string
+	// Required makes an absent param a violation.
+	Required bool
+	// MinCount and MaxCount limit the number of occurrences of a present param, a zero MaxCount means no limit.
+	// A MinCount above zero makes the param required.
+	MinCount, MaxCount int
+	// Pattern is a regexp that the value must match.
+	Pattern *regexp.Regexp
+	// Enum lists the allowed values.
+	Enum []string
+	// Range makes the value a number within the range.
+	Range *NumberRange
+}
+
+// NumberRange is a closed range of numbers. Use [math.Inf] for an unbounded side.
+type NumberRange struct {
+	Min, Max float64
+}
+
+// Validate checks the params against the schema. It returns nil if they are valid,
+// or a [ValidationErrors] list with every violation otherwise.
+// The violations of the params come first in the order of the params,
+// followed by the missing params in the order of the schema.
+func (s *Schema) Validate(params Params) error {
+	return s.validate(params, nil)
+}
+
+// ValidateQuery parses the query string with [ParseParams] and checks the params against the schema,
+// see [Schema.Validate]. The parse errors are returned along with the violations,
+// and the values of the params that failed to be parsed are not checked.
+func (s *Schema) ValidateQuery(query string) error {
+	params, err := ParseParams(query)
+	var parseErrs ParseErrors
+	var failed map[int]bool
+	if errors.As(err, &parseErrs) {
+		failed = make(map[int]bool, len(parseErrs))
+		for _, parseErr := range parseErrs {
+			failed[parseErr.Index] = true
+		}
+	}
+	return errors.Join(err, s.validate(params, failed))
+}
+
+// validate checks the params against the schema, skipping the value checks of the params at the failed positions.
+func (s *Schema) validate(params Params, failed map[int]bool) error {
+	declared := make(map[string]*ParamSchema, len(s.Params))
+	for i := range s.Params {
+		if _, ok := declared[s.Params[i].Key]; !ok {
+			declared[s.Params[i].Key] = &s.Params[i]
+		}
+	}
+	// exclusive maps a key to the groups it belongs to, present maps a group to the first key of it that is present.
+	exclusive := make(map[string][]int)
+	for g, group := range s.Exclusive {
+		for _, key := range group {
+			exclusive[key] = append(exclusive[key], g)
+		}
+	}
+	present := make(map[int]string)
+
+	var errs ValidationErrors
+	report := func(key string, pos int, value string, err error) {
+		errs = append(errs, &ValidationError{Key: key, Pos: pos, Value: value, Err: err})
+	}
+	count := make(map[string]int, len(params))
+	for pos, param := range params {
+		key, value := param.Key, param.Value
+		count[key]++
+		if count[key] == 1 {
+			for _, g := range exclusive[key] {
+				if first, ok := present[g]; !ok {
+					present[g] = key
+				} else if first != key {
+					report(key, pos, value, fmt.Errorf("%w: %q and %q", ErrParamExclusive, first, key))
+				}
+			}
+		}
+
+		ps := declared[key]
+		if ps == nil {
+			if s.RejectUnknown && (s.AllowKeys == nil || !s.AllowKeys.Match(key)) {
+				report(key, pos, value, ErrParamUnknown)
+			}
+			continue
+		}
+		if ps.MaxCount > 0 && count[key] == ps.MaxCount+1 {
+			report(key, pos, value, fmt.Errorf("%w: want at most %d", ErrParamCount, ps.MaxCount))
+		}
+		if failed[pos] {
+			continue
+		}
+		for _, err := range ps.checkValue(value) {
+			report(key, pos, value, err)
+		}
+	}
+
+	for i := range s.Params {
+		ps := &s.Params[i]
+		if declared[ps.Key] != ps {
+			continue
+		}
+		switch n := count[ps.Key]; {
+		case n == 0 && (ps.Required || ps.MinCount > 0):
+			report(ps.Key, -1, "", ErrParamRequired)
+		case n < ps.MinCount:
+			report(ps.Key, -1, "", fmt.Errorf("%w: got %d, want at least %d", ErrParamCount, n, ps.MinCount))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// checkValue checks a value of the param and returns the failed checks.
+func (ps *ParamSchema) checkValue(value string) []error {
+	var errs []error
+	if ps.Pattern != nil && !ps.Pattern.MatchString(value) {
+		errs = append(errs, fmt.Errorf("%w `%s`: %q", ErrParamPattern, ps.Pattern, value))
+	}
+	if len(ps.Enum) > 0 && !containsString(ps.Enum, value) {
+		errs = append(errs, fmt.Errorf("%w: %q, want one of %q", ErrParamEnum, value, ps.Enum))
+	}
+	if r := ps.Range; r != nil {
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(n) || n < r.Min || n > r.Max {
+			errs = append(errs, fmt.Errorf("%w: %q, want a number in [%g, %g]", ErrParamRange, value, r.Min, r.Max))
+		}
+	}
+	return errs
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package urlqm
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"testing"
+)
+
+var testSchema = &Schema{
+	Params: []ParamSchema{
+		{Key: "q", Required: true, MaxCount: 1},
+		{Key: "page", MaxCount: 1, Range: &NumberRange{Min: 1, Max: 1000}},
+		{Key: "sort", Enum: []string{"date", "name"}},
+		{Key: "tag", MinCount: 2, MaxCount: 3, Pattern: regexp.MustCompile(`^[a-z]+$`)},
+		{Key: "cursor"},
+		{Key: "offset", Range: &NumberRange{Min: 0, Max: math.Inf(1)}},
+		{Key: "debug"},
+		{Key: "q", Enum: []string{"ignored"}},
+	},
+	RejectUnknown: true,
+	AllowKeys:     PrefixRule("utm_"),
+	Exclusive:     [][]string{{"page", "cursor", "offset"}},
+}
+
+func TestSchema_Validate(t *testing.T) {
+	type violation struct {
+		key   string
+		pos   int
+		value string
+		err   error
+	}
+	tests := []struct {
+		name  string
+		query string
+		want  []violation
+	}{
+		{
+			name:  "Valid",
+			query: "q=go&page=2&sort=date&tag=go&tag=url&utm_source=x&debug",
+		},
+		{
+			name:  "Empty",
+			query: "",
+			want: []violation{
+				{key: "q", pos: -1, err: ErrParamRequired},
+				{key: "tag", pos: -1, err: ErrParamRequired},
+			},
+		},
+		{
+			name:  "Unknown",
+			query: "q=go&tag=a&tag=b&x=1&utm_source=2&utm=3",
+			want: []violation{
+				{key: "x", pos: 3, value: "1", err: ErrParamUnknown},
+				{key: "utm", pos: 5, value: "3", err: ErrParamUnknown},
+			},
+		},
+		{
+			name:  "Counts",
+			query: "q=go&tag=a&q=rust&q=c",
+			want: []violation{
+				{key: "q", pos: 2, value: "rust", err: ErrParamCount},
+				{key: "tag", pos: -1, err: ErrParamCount},
+			},
+		},
+		{
+			name:  "Too many",
+			query: "q=go&tag=a&tag=b&tag=c&tag=d&tag=e",
+			want: []violation{
+				{key: "tag", pos: 4, value: "d", err: ErrParamCount},
+			},
+		},
+		{
+			name:  "Values",
+			query: "q=go&tag=a&tag=B1&sort=size&page=0&page=abc&offset=-1&offset=NaN",
+			want: []violation{
+				{key: "tag", pos: 2, value: "B1", err: ErrParamPattern},
+				{key: "sort", pos: 3, value: "size", err: ErrParamEnum},
+				{key: "page", pos: 4, value: "0", err: ErrParamRange},
+				{key: "page", pos: 5, value: "abc", err: ErrParamCount},
+				{key: "page", pos: 5, value: "abc", err: ErrParamRange},
+				{key: "offset", pos: 6, value: "-1", err: ErrParamExclusive},
+				{key: "offset", pos: 6, value: "-1", err: ErrParamRange},
+				{key: "offset", pos: 7, value: "NaN", err: ErrParamRange},
+			},
+		},
+		{
+			name:  "Exclusive",
+			query: "q=go&tag=a&tag=b&cursor=x&page=2&cursor=y&offset=10",
+			want: []violation{
+				{key: "page", pos: 4, value: "2", err: ErrParamExclusive},
+				{key: "offset", pos: 6, value: "10", err: ErrParamExclusive},
+			},
+		},
+		{
+			name:  "Flag value",
+			query: "q&tag=a&tag=b&sort",
+			want: []violation{
+				{key: "sort", pos: 3, value: "", err: ErrParamEnum},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testSchema.ValidateQuery(tt.query)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("ValidateQuery() error = %v", err)
+				}
+				return
+			}
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("ValidateQuery() error = %v, want ValidationErrors", err)
+			}
+			if len(errs) != len(tt.want) {
+				t.Fatalf("ValidateQuery() error = %v, want %d violations", err, len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := errs[i]
+				if got.Key != want.key || got.Pos != want.pos || got.Value != want.value || !errors.Is(got, want.err) {
+					t.Errorf("violation %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSchema_ValidateErrors(t *testing.T) {
+	err := testSchema.ValidateQuery("q=%zz&tag=a&tag=b&sort=size")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Index != 0 {
+		t.Errorf("ValidateQuery() error = %v, want a *ParseError", err)
+	}
+	if !errors.Is(err, ErrParamEnum) {
+		t.Errorf("ValidateQuery() error = %v, want %v", err, ErrParamEnum)
+	}
+
+	err = testSchema.ValidateQuery("q=go&tag=a&tag=b&page=%GG")
+	var errs ValidationErrors
+	if !errors.As(err, &parseErr) || parseErr.Index != 3 || errors.As(err, &errs) {
+		t.Errorf("ValidateQuery() error = %v, want only a *ParseError", err)
+	}
+
+	err = testSchema.ValidateQuery("tag=a&tag=b&sort=size")
+	want := `urlqm: parameter "sort" at position 2: value is not allowed: "size", want one of ["date" "name"]; ` +
+		`urlqm: parameter "q": parameter is required`
+	if err == nil || err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err, want)
+	}
+
+	params := Params{{Key: "q", Value: "go"}, {Key: "tag", Value: "a"}, {Key: "tag", Value: "b"}, {Key: "x", Flag: true}}
+	if err := (&Schema{Params: testSchema.Params}).Validate(params); err != nil {
+		t.Errorf("Validate() without RejectUnknown error = %v", err)
+	}
+	if err := (&Schema{}).Validate(params); err != nil {
+		t.Errorf("Validate() with an empty schema error = %v", err)
+	}
+}
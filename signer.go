@@ -0,0 +1,194 @@
+package urlqm
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Signer signs query strings with HMAC and verifies them, e.g. to hand out presigned download links.
+//
+// The signature covers the decoded params of the query, sorted by key (stable, so the order of repeated keys counts)
+// and encoded with [RFC3986Escaper], so it doesn't depend on how the query is escaped on the way.
+// It also covers the expiry time and the key ID, which are added as params before signing.
+//
+// To rotate keys, sign with a new Key and KeyID and move the previous key to Keys under its ID:
+//
+//	s := Signer{Key: newKey, KeyID: "2", Keys: map[string][]byte{"1": oldKey}}
+//
+// The zero value has no key and can't sign.
+type Signer struct {
+	// Key is the key that signs queries. A query signed with it can be verified too.
+	Key []byte
+	// KeyID is the ID of Key. If it is not empty, it is added to signed queries as the key ID param.
+	KeyID string
+	// Keys are the keys that only verify queries, by their IDs. A query without the key ID param
+	// is verified with the key that has an empty ID.
+	Keys map[string][]byte
+
+	// Hash is the hash function of HMAC. If it is nil, [sha256.New] is used.
+	Hash func() hash.Hash
+
+	// ExpiresParam, KeyIDParam and SignatureParam are the keys of the params added by [Signer.Sign].
+	// They are "expires", "kid" and "signature" by default.
+	ExpiresParam, KeyIDParam, SignatureParam string
+
+	// Parser parses the queries. Its Separators and KeepPlus are respected.
+	Parser Parser
+}
+
+// Sign returns the query with the expiry time, the key ID and the signature params appended.
+// The expiry time is a Unix time in seconds; a zero expires makes a signature that never expires.
+// The params that a previous signing added are replaced. A malformed query returns a [ParseErrors].
+func (s Signer) Sign(query string, expires time.Time) (string, error) {
+	return s.sign("", query, expires)
+}
+
+// SignURL signs the raw query of the URL in place, like [Signer.Sign] does.
+// The escaped path of the URL is signed too, so the signature is not valid for another path.
+func (s Signer) SignURL(u *url.URL, expires time.Time) error {
+	query, err := s.sign(u.EscapedPath(), u.RawQuery, expires)
+	if err != nil {
+		return err
+	}
+	u.RawQuery = query
+	return nil
+}
+
+// Verify checks the signature of a query signed by [Signer.Sign]. It returns nil for a valid signature,
+// [ErrUnsigned] if there is no signature, [ErrUnknownKeyID] if there is no key to check it with,
+// [ErrInvalidSignature] if it doesn't match and [ErrSignatureExpired] if it matches but has expired.
+// Signatures are compared in constant time.
+func (s Signer) Verify(query string) error {
+	return s.verify("", query, time.Now())
+}
+
+// VerifyURL checks the signature of a URL signed by [Signer.SignURL]. See [Signer.Verify].
+func (s Signer) VerifyURL(u *url.URL) error {
+	return s.verify(u.EscapedPath(), u.RawQuery, time.Now())
+}
+
+func (s Signer) sign(path, query string, expires time.Time) (string, error) {
+	if len(s.Key) == 0 {
+		return "", ErrNoSigningKey
+	}
+	p := s.parser()
+	params, err := p.ParseQuery(query)
+	if err != nil {
+		return "", err
+	}
+	expiresKey, keyIDKey, signatureKey := s.expiresParam(), s.keyIDParam(), s.signatureParam()
+	isSignParam := func(key string) bool {
+		return key == expiresKey || key == keyIDKey || key == signatureKey
+	}
+	params.deleteFunc(func(param Param) bool { return isSignParam(param.Key) })
+	p.DecodeKeys = true
+	p.deleteQueryParamsFunc(&query, isSignParam)
+
+	if !expires.IsZero() {
+		exp := strconv.FormatInt(expires.Unix(), 10)
+		params.Add(expiresKey, exp)
+		p.AddQueryParam(&query, expiresKey, exp)
+	}
+	if s.KeyID != "" {
+		params.Add(keyIDKey, s.KeyID)
+		p.AddQueryParam(&query, keyIDKey, s.KeyID)
+	}
+	mac := s.mac(s.Key, path, params)
+	p.AddQueryParam(&query, signatureKey, base64.RawURLEncoding.EncodeToString(mac))
+	return query, nil
+}
+
+func (s Signer) verify(path, query string, now time.Time) error {
+	params, err := s.parser().ParseQuery(query)
+	if err != nil {
+		return err
+	}
+	signature, found := params.LookupExtract(s.signatureParam())
+	if !found {
+		return ErrUnsigned
+	}
+	if params.Has(s.signatureParam()) {
+		return ErrInvalidSignature
+	}
+	key := s.verifyingKey(params.Get(s.keyIDParam()))
+	if key == nil {
+		return ErrUnknownKeyID
+	}
+	got, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(got, s.mac(key, path, params)) {
+		return ErrInvalidSignature
+	}
+
+	exp, found := params.Lookup(s.expiresParam())
+	if !found {
+		return nil
+	}
+	sec, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if now.Unix() > sec {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+// mac returns the HMAC of the path and the canonical form of the params.
+func (s Signer) mac(key []byte, path string, params Params) []byte {
+	sorted := append(make(Params, 0, len(params)), params...)
+	SortKeys(&sorted)
+	hashFunc := s.Hash
+	if hashFunc == nil {
+		hashFunc = sha256.New
+	}
+	m := hmac.New(hashFunc, key)
+	io.WriteString(m, path)
+	io.WriteString(m, "\n")
+	io.WriteString(m, EncodeParamsWith(sorted, RFC3986Escaper))
+	return m.Sum(nil)
+}
+
+func (s Signer) verifyingKey(keyID string) []byte {
+	if keyID == s.KeyID && len(s.Key) > 0 {
+		return s.Key
+	}
+	if key := s.Keys[keyID]; len(key) > 0 {
+		return key
+	}
+	return nil
+}
+
+// parser returns the parser of the queries, which doesn't keep the original form of params,
+// so they are always encoded in the canonical form.
+func (s Signer) parser() Parser {
+	p := s.Parser
+	p.KeepRaw, p.Strict, p.DecodeKeys = false, false, false
+	return p
+}
+
+func (s Signer) expiresParam() string {
+	if s.ExpiresParam == "" {
+		return "expires"
+	}
+	return s.ExpiresParam
+}
+
+func (s Signer) keyIDParam() string {
+	if s.KeyIDParam == "" {
+		return "kid"
+	}
+	return s.KeyIDParam
+}
+
+func (s Signer) signatureParam() string {
+	if s.SignatureParam == "" {
+		return "signature"
+	}
+	return s.SignatureParam
+}
@@ -0,0 +1,262 @@
+package urlqm
+
+import (
+	"crypto/sha1"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSigner_Sign(t *testing.T) {
+	expires := time.Unix(1700000000, 0)
+	tests := []struct {
+		name    string
+		signer  Signer
+		query   string
+		expires time.Time
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "Expires",
+			signer:  Signer{Key: []byte("secret")},
+			query:   "file=report.pdf&user=42",
+			expires: expires,
+			want:    "file=report.pdf&user=42&expires=1700000000&signature=",
+		},
+		{
+			name:   "Key ID and no expiry",
+			signer: Signer{Key: []byte("secret"), KeyID: "k2"},
+			query:  "file=report.pdf",
+			want:   "file=report.pdf&kid=k2&signature=",
+		},
+		{
+			name:    "Empty query",
+			signer:  Signer{Key: []byte("secret")},
+			query:   "",
+			expires: expires,
+			want:    "expires=1700000000&signature=",
+		},
+		{
+			name:    "Re-signing replaces the params",
+			signer:  Signer{Key: []byte("secret"), KeyID: "k2"},
+			query:   "expires=1&file=a&kid=k1&signature=abc",
+			expires: expires,
+			want:    "file=a&expires=1700000000&kid=k2&signature=",
+		},
+		{
+			name: "Custom params",
+			signer: Signer{Key: []byte("secret"), KeyID: "k1",
+				ExpiresParam: "X-Expires", KeyIDParam: "X-Key", SignatureParam: "X-Signature"},
+			query:   "file=a",
+			expires: expires,
+			want:    "file=a&X-Expires=1700000000&X-Key=k1&X-Signature=",
+		},
+		{
+			name:    "Custom separator",
+			signer:  Signer{Key: []byte("secret"), Parser: Parser{Separators: ";"}},
+			query:   "file=a",
+			expires: expires,
+			want:    "file=a;expires=1700000000;signature=",
+		},
+		{
+			name:    "No key",
+			query:   "file=a",
+			wantErr: ErrNoSigningKey,
+		},
+		{
+			name:    "Malformed query",
+			signer:  Signer{Key: []byte("secret")},
+			query:   "file=%zz",
+			wantErr: &ParseError{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.signer.Sign(tt.query, tt.expires)
+			if tt.wantErr != nil {
+				var parseErr *ParseError
+				if !errors.Is(err, tt.wantErr) && !(errors.As(tt.wantErr, &parseErr) && errors.As(err, &parseErr)) {
+					t.Errorf("Sign() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("Sign() = %q, want prefix %q", got, tt.want)
+			}
+			if err := tt.signer.verify("", got, expires.Add(-time.Minute)); err != nil {
+				t.Errorf("verify() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestSigner_Verify(t *testing.T) {
+	expires := time.Unix(1700000000, 0)
+	before := expires.Add(-time.Second)
+	signer := Signer{Key: []byte("secret"), KeyID: "k2", Keys: map[string][]byte{"k1": []byte("old secret")}}
+	signed, err := signer.Sign("file=a+b.pdf&tag=x&tag=y", expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldSigned, err := Signer{Key: []byte("old secret"), KeyID: "k1"}.Sign("file=a", expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noIDSigned, err := Signer{Key: []byte("legacy")}.Sign("file=a", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := signed[strings.LastIndex(signed, "=")+1:]
+
+	tests := []struct {
+		name    string
+		signer  Signer
+		query   string
+		now     time.Time
+		wantErr error
+	}{
+		{
+			name:  "Valid",
+			query: signed,
+			now:   before,
+		},
+		{
+			name:  "Valid at expiry",
+			query: signed,
+			now:   expires,
+		},
+		{
+			name:  "Valid with another escaping and order",
+			query: "signature=" + signature + "&tag=x&kid=k2&expires=1700000000&tag=y&file=a%20b%2Epdf",
+			now:   before,
+		},
+		{
+			name:  "Rotated key",
+			query: oldSigned,
+			now:   before,
+		},
+		{
+			name:   "Key without ID",
+			signer: Signer{Keys: map[string][]byte{"": []byte("legacy")}},
+			query:  noIDSigned,
+			now:    expires.Add(time.Hour * 24 * 365),
+		},
+		{
+			name:    "Expired",
+			query:   signed,
+			now:     expires.Add(time.Second),
+			wantErr: ErrSignatureExpired,
+		},
+		{
+			name:    "Changed value",
+			query:   strings.Replace(signed, "tag=y", "tag=z", 1),
+			now:     before,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "Reordered values of a key",
+			query:   strings.Replace(signed, "tag=x&tag=y", "tag=y&tag=x", 1),
+			now:     before,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "Added param",
+			query:   signed + "&admin=1",
+			now:     before,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "Extended expiry",
+			query:   strings.Replace(signed, "expires=1700000000", "expires=1800000000", 1),
+			now:     before,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "Removed expiry",
+			query:   strings.Replace(signed, "expires=1700000000&", "", 1),
+			now:     before,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "Malformed signature",
+			query:   signed + "!",
+			now:     before,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "Second signature",
+			query:   signed + "&signature=" + signature,
+			now:     before,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "Unsigned",
+			query:   "file=a&expires=1700000000",
+			now:     before,
+			wantErr: ErrUnsigned,
+		},
+		{
+			name:    "Unknown key ID",
+			query:   strings.Replace(signed, "kid=k2", "kid=k3", 1),
+			now:     before,
+			wantErr: ErrUnknownKeyID,
+		},
+		{
+			name:    "No key ID",
+			query:   noIDSigned,
+			now:     before,
+			wantErr: ErrUnknownKeyID,
+		},
+		{
+			name:    "Another hash",
+			signer:  Signer{Key: []byte("secret"), KeyID: "k2", Hash: sha1.New},
+			query:   signed,
+			now:     before,
+			wantErr: ErrInvalidSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.signer
+			if s.Key == nil && s.Keys == nil {
+				s = signer
+			}
+			if err := s.verify("", tt.query, tt.now); !errors.Is(err, tt.wantErr) {
+				t.Errorf("verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSigner_URL(t *testing.T) {
+	signer := Signer{Key: []byte("secret")}
+	u, _ := url.Parse("https://example.com/files/report.pdf?user=42")
+	if err := signer.SignURL(u, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := signer.VerifyURL(u); err != nil {
+		t.Errorf("VerifyURL() error = %v", err)
+	}
+	if err := signer.Verify(u.RawQuery); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrInvalidSignature)
+	}
+
+	other := *u
+	other.Path = "/files/secret.pdf"
+	if err := signer.VerifyURL(&other); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifyURL() for another path error = %v, want %v", err, ErrInvalidSignature)
+	}
+
+	if err := signer.SignURL(u, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := signer.VerifyURL(u); !errors.Is(err, ErrSignatureExpired) {
+		t.Errorf("VerifyURL() error = %v, want %v", err, ErrSignatureExpired)
+	}
+}
@@ -0,0 +1,293 @@
+package urlqm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Style is an OpenAPI 3 serialization style of a query parameter.
+type Style int
+
+const (
+	// StyleForm serializes arrays as `key=a&key=b` if exploded, or as `key=a,b` otherwise.
+	StyleForm Style = iota
+	// StyleSpaceDelimited serializes arrays as `key=a%20b`. Exploded, it is the same as [StyleForm].
+	StyleSpaceDelimited
+	// StylePipeDelimited serializes arrays as `key=a|b`. Exploded, it is the same as [StyleForm].
+	StylePipeDelimited
+	// StyleDeepObject serializes objects as `key[R]=100&key[G]=200`. It is defined only for exploded objects.
+	StyleDeepObject
+)
+
+func (s Style) String() string {
+	switch s {
+	case StyleForm:
+		return "form"
+	case StyleSpaceDelimited:
+		return "spaceDelimited"
+	case StylePipeDelimited:
+		return "pipeDelimited"
+	case StyleDeepObject:
+		return "deepObject"
+	}
+	return fmt.Sprintf("Style(%d)", int(s))
+}
+
+// delimiter returns the delimiter of non-exploded values, as it appears in the query string.
+func (s Style) delimiter() string {
+	switch s {
+	case StyleSpaceDelimited:
+		return "%20"
+	case StylePipeDelimited:
+		return "|"
+	}
+	return ","
+}
+
+// decodedDelimiter returns the delimiter of the style as it is in a decoded value.
+func (s Style) decodedDelimiter() string {
+	if s == StyleSpaceDelimited {
+		return " "
+	}
+	return s.delimiter()
+}
+
+// SetStyled sets an array param with given key, serialized in the given OpenAPI style.
+// Like [Params.Set], it replaces any existing param with the same key and takes the place of the first one.
+//
+// Non-exploded values are joined into a single param, like `key=a,b`. On encoding, the delimiters stay as they are
+// while the values are escaped with the [Escaper] of the encoding, the delimiters inside them included,
+// e.g. []string{"a,b", "c"} makes `key=a%2Cb,c`. An empty array makes `key=`.
+// [StyleDeepObject] is not defined for arrays and returns [ErrUnsupportedStyle].
+func (p *Params) SetStyled(key string, values []string, style Style, explode bool) error {
+	if style == StyleDeepObject || style < StyleForm || style > StyleDeepObject {
+		return fmt.Errorf("%w: %v array", ErrUnsupportedStyle, style)
+	}
+	if key == "" {
+		return nil
+	}
+	var params []Param
+	if explode && len(values) > 0 {
+		for _, value := range values {
+			params = append(params, Param{Key: key, Value: value})
+		}
+	} else {
+		params = append(params, joinStyled(key, values, style))
+	}
+	p.replaceStyled(func(k string) bool { return k == key }, params)
+	return nil
+}
+
+// GetStyled returns the values of an array param with given key, serialized in the given OpenAPI style,
+// or nil if there is no such param. A non-exploded param with an empty value, like `key=`, is an empty array.
+// Non-exploded values are split by their delimiter. If the param has its original form, that is,
+// it was parsed with [Parser.KeepRaw] or set by [Params.SetStyled] and not modified since,
+// the delimiter is looked for in that form, so escaped delimiters stay inside the values.
+// Otherwise the decoded value is split, and escaped delimiters split it as well.
+// [StyleDeepObject] is not defined for arrays and returns [ErrUnsupportedStyle].
+func (p Params) GetStyled(key string, style Style, explode bool) ([]string, error) {
+	if style == StyleDeepObject || style < StyleForm || style > StyleDeepObject {
+		return nil, fmt.Errorf("%w: %v array", ErrUnsupportedStyle, style)
+	}
+	if explode {
+		return p.GetAll(key), nil
+	}
+	for _, param := range p {
+		if param.Key == key {
+			if param.Value == "" {
+				return []string{}, nil
+			}
+			return splitStyled(param, style)
+		}
+	}
+	return nil, nil
+}
+
+// SetStyledObject sets an object param with given key, serialized in the given OpenAPI style.
+// The fields of the object are given as params, so their order is kept.
+// It replaces the params that a previous object with the same key was serialized to,
+// and takes the place of the first one.
+//
+// The supported combinations are:
+//   - [StyleForm] exploded, as `R=100&G=200` (the key is not used);
+//   - [StyleForm], [StyleSpaceDelimited] and [StylePipeDelimited] not exploded, as `key=R,100,G,200`;
+//   - [StyleDeepObject] exploded, as `key[R]=100&key[G]=200`.
+//
+// Other combinations are not defined by OpenAPI and return [ErrUnsupportedStyle].
+func (p *Params) SetStyledObject(key string, fields []Param, style Style, explode bool) error {
+	if err := checkObjectStyle(style, explode); err != nil {
+		return err
+	}
+	if key == "" {
+		return nil
+	}
+	var params []Param
+	var match func(k string) bool
+	switch {
+	case style == StyleDeepObject:
+		for _, f := range fields {
+			params = append(params, newStyledParam(key+"["+f.Key+"]", f.Value,
+				&styledForm{key: key, field: f.Key, deep: true, values: []string{f.Value}}))
+		}
+		match = func(k string) bool { return isDeepObjectKey(k, key) }
+	case explode:
+		params = make([]Param, len(fields))
+		for i, f := range fields {
+			params[i] = Param{Key: f.Key, Value: f.Value}
+		}
+		match = func(k string) bool {
+			for _, f := range fields {
+				if f.Key == k {
+					return true
+				}
+			}
+			return false
+		}
+	default:
+		values := make([]string, 0, len(fields)*2)
+		for _, f := range fields {
+			values = append(values, f.Key, f.Value)
+		}
+		params = append(params, joinStyled(key, values, style))
+		match = func(k string) bool { return k == key }
+	}
+	p.replaceStyled(match, params)
+	return nil
+}
+
+// GetStyledObject returns the fields of an object param with given key, serialized in the given OpenAPI style,
+// or nil if there is no such param. See [Params.SetStyledObject] for the styles.
+// An exploded [StyleForm] object can't be told apart from other params, so it returns [ErrUnsupportedStyle].
+// A non-exploded object with an odd number of items returns [ErrMalformedStyled].
+func (p Params) GetStyledObject(key string, style Style, explode bool) ([]Param, error) {
+	if err := checkObjectStyle(style, explode); err != nil {
+		return nil, err
+	}
+	if style == StyleDeepObject {
+		var fields []Param
+		for _, param := range p {
+			if isDeepObjectKey(param.Key, key) {
+				fields = append(fields, Param{Key: param.Key[len(key)+1 : len(param.Key)-1], Value: param.Value})
+			}
+		}
+		return fields, nil
+	}
+	if explode {
+		return nil, fmt.Errorf("%w: exploded %v object can't be read back", ErrUnsupportedStyle, style)
+	}
+
+	values, err := p.GetStyled(key, style, false)
+	if err != nil || values == nil {
+		return nil, err
+	}
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("%w: %v object %q has an odd number of items", ErrMalformedStyled, style, key)
+	}
+	fields := make([]Param, 0, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		fields = append(fields, Param{Key: values[i], Value: values[i+1]})
+	}
+	return fields, nil
+}
+
+func checkObjectStyle(style Style, explode bool) error {
+	switch {
+	case style == StyleForm,
+		(style == StyleSpaceDelimited || style == StylePipeDelimited) && !explode,
+		style == StyleDeepObject && explode:
+		return nil
+	}
+	return fmt.Errorf("%w: %v object with explode=%t", ErrUnsupportedStyle, style, explode)
+}
+
+func isDeepObjectKey(k, key string) bool {
+	return len(k) > len(key)+1 && strings.HasPrefix(k, key) && k[len(key)] == '[' && k[len(k)-1] == ']'
+}
+
+// joinStyled returns a param with the values joined by the delimiter of the style.
+// The delimiters are not escaped on encoding.
+func joinStyled(key string, values []string, style Style) Param {
+	return newStyledParam(key, strings.Join(values, style.decodedDelimiter()), &styledForm{key: key, values: append([]string(nil), values...), delim: style.delimiter()})
+}
+
+// styledForm is the decoded form of a param set in an OpenAPI style,
+// which is escaped on encoding, all but its brackets and delimiters.
+type styledForm struct {
+	// key and field make the key, like `key[field]` if deep is set.
+	key, field string
+	deep       bool
+	// values are joined by delim, which is already escaped.
+	values []string
+	delim  string
+}
+
+// encode returns the key and the value of the param escaped with e.
+func (f *styledForm) encode(e Escaper) (key, value string) {
+	key = e.Escape(f.key)
+	if f.deep {
+		key += "[" + e.Escape(f.field) + "]"
+	}
+	var buf strings.Builder
+	for i, v := range f.values {
+		if i > 0 {
+			buf.WriteString(f.delim)
+		}
+		buf.WriteString(e.Escape(v))
+	}
+	return key, buf.String()
+}
+
+// newStyledParam returns a param with the given styled form, which [EncodeParamsWith] escapes with its [Escaper].
+func newStyledParam(key, value string, f *styledForm) Param {
+	param := newRawParam(key, value, "", "")
+	param.raw.key, param.raw.value = f.encode(FormEscaper)
+	param.raw.styled = f
+	return param
+}
+
+// newRawParam returns a param with the given original form, which is emitted by [EncodeParams] as is.
+func newRawParam(key, value, rawKey, rawValue string) Param {
+	return Param{Key: key, Value: value, raw: &rawParam{key: rawKey, value: rawValue, decKey: key, decValue: value}}
+}
+
+// hasRawValue tells whether the param has the original form of its value and was not modified since.
+func hasRawValue(param Param) bool {
+	raw := param.raw
+	return raw != nil && !raw.flag && param.Key == raw.decKey && param.Value == raw.decValue
+}
+
+// splitStyled splits the value of the param by the delimiter of the style,
+// in its original form if the param has it, or in the decoded form otherwise.
+func splitStyled(param Param, style Style) ([]string, error) {
+	if !hasRawValue(param) {
+		return strings.Split(param.Value, style.decodedDelimiter()), nil
+	}
+	parts := strings.Split(param.raw.value, style.delimiter())
+	for i, part := range parts {
+		value, err := unescape(part, true)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = value
+	}
+	return parts, nil
+}
+
+// replaceStyled removes the params whose keys match and puts the new params in place of the first removed one,
+// or at the end if nothing was removed.
+func (p *Params) replaceStyled(match func(key string) bool, params []Param) {
+	at := -1
+	for i, param := range *p {
+		if match(param.Key) {
+			at = i
+			break
+		}
+	}
+	if at < 0 {
+		*p = append(*p, params...)
+		return
+	}
+	p.deleteFunc(func(param Param) bool { return match(param.Key) })
+	rest := append(params, (*p)[at:]...)
+	*p = append((*p)[:at], rest...)
+}
@@ -0,0 +1,166 @@
+package urlqm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParams_SetStyled(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		style   Style
+		explode bool
+		want    string
+		wantErr error
+	}{
+		{name: "Form exploded", values: []string{"a", "b"}, style: StyleForm, explode: true, want: "x=1&key=a&key=b&y=2"},
+		{name: "Form", values: []string{"a", "b"}, style: StyleForm, want: "x=1&key=a,b&y=2"},
+		{name: "Form escapes delimiters in values", values: []string{"a,b", "c d"}, style: StyleForm, want: "x=1&key=a%2Cb,c+d&y=2"},
+		{name: "Space delimited", values: []string{"a", "b c"}, style: StyleSpaceDelimited, want: "x=1&key=a%20b+c&y=2"},
+		{name: "Pipe delimited", values: []string{"a", "b|c"}, style: StylePipeDelimited, want: "x=1&key=a|b%7Cc&y=2"},
+		{name: "Pipe delimited exploded", values: []string{"a", "b"}, style: StylePipeDelimited, explode: true, want: "x=1&key=a&key=b&y=2"},
+		{name: "Empty array", values: nil, style: StyleForm, want: "x=1&key=&y=2"},
+		{name: "Empty array exploded", values: nil, style: StyleForm, explode: true, want: "x=1&key=&y=2"},
+		{name: "Deep object", values: []string{"a"}, style: StyleDeepObject, explode: true, want: "x=1&key=old&y=2&key=old2", wantErr: ErrUnsupportedStyle},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Params{{Key: "x", Value: "1"}, {Key: "key", Value: "old"}, {Key: "y", Value: "2"}, {Key: "key", Value: "old2"}}
+			err := p.SetStyled("key", tt.values, tt.style, tt.explode)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Params.SetStyled() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := p.Encode(); got != tt.want {
+				t.Errorf("Params.SetStyled() = %q, want %q", got, tt.want)
+			}
+			if err != nil {
+				return
+			}
+
+			// the values must be read back from the params and from the parsed query.
+			want := tt.values
+			if want == nil && !tt.explode {
+				want = []string{}
+			} else if want == nil {
+				want = []string{""}
+			}
+			if got, err := p.GetStyled("key", tt.style, tt.explode); err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("Params.GetStyled() = %q, %v, want %q", got, err, want)
+			}
+			parsed, _ := Parser{KeepRaw: true}.ParseQuery(p.Encode())
+			if got, err := parsed.GetStyled("key", tt.style, tt.explode); err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("Params.GetStyled() of parsed query = %q, %v, want %q", got, err, want)
+			}
+		})
+	}
+}
+
+func TestParams_GetStyled(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		parser  Parser
+		style   Style
+		explode bool
+		want    []string
+		wantErr error
+	}{
+		{name: "Absent", query: "a=1", style: StyleForm, want: nil},
+		{name: "Form", query: "key=a,b,c", parser: Parser{KeepRaw: true}, style: StyleForm, want: []string{"a", "b", "c"}},
+		{name: "Form raw commas", query: "key=a%2Cb,c", parser: Parser{KeepRaw: true}, style: StyleForm, want: []string{"a,b", "c"}},
+		{name: "Not raw", query: "ids=1,2,3&key=a,b,c", style: StyleForm, want: []string{"a", "b", "c"}},
+		{name: "Not raw escaped commas", query: "key=a%2Cb,c", style: StyleForm, want: []string{"a", "b", "c"}},
+		{name: "Space delimited not raw", query: "key=a%20b+c", style: StyleSpaceDelimited, want: []string{"a", "b", "c"}},
+		{name: "Empty not raw", query: "key=", style: StyleForm, want: []string{}},
+		{name: "Space delimited", query: "key=a%20b", parser: Parser{KeepRaw: true}, style: StyleSpaceDelimited, want: []string{"a", "b"}},
+		{name: "Space delimited plus", query: "key=a+b%20c", parser: Parser{KeepRaw: true}, style: StyleSpaceDelimited, want: []string{"a b", "c"}},
+		{name: "Pipe delimited", query: "key=a|b&key=c", parser: Parser{KeepRaw: true}, style: StylePipeDelimited, want: []string{"a", "b"}},
+		{name: "Exploded", query: "key=a|b&key=c", style: StylePipeDelimited, explode: true, want: []string{"a|b", "c"}},
+		{name: "Deep object", query: "key=a", style: StyleDeepObject, explode: true, wantErr: ErrUnsupportedStyle},
+		{name: "Unknown style", query: "key=a", style: Style(10), wantErr: ErrUnsupportedStyle},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := tt.parser.ParseQuery(tt.query)
+			got, err := p.GetStyled("key", tt.style, tt.explode)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Params.GetStyled() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Params.GetStyled() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParams_StyledEscaper(t *testing.T) {
+	var p Params
+	_ = p.SetStyled("list", []string{"a b", "c,d"}, StyleForm, false)
+	_ = p.SetStyled("words", []string{"a b", "c"}, StyleSpaceDelimited, false)
+	_ = p.SetStyledObject("my obj", []Param{{Key: "a b", Value: "c d"}}, StyleDeepObject, true)
+	if got, want := p.Encode(), "list=a+b,c%2Cd&words=a+b%20c&my+obj[a+b]=c+d"; got != want {
+		t.Errorf("Encode() = %q, want %q", got, want)
+	}
+	if got, want := p.EncodeWith(RFC3986Escaper), "list=a%20b,c%2Cd&words=a%20b%20c&my%20obj[a%20b]=c%20d"; got != want {
+		t.Errorf("EncodeWith() = %q, want %q", got, want)
+	}
+
+	p.Set("list", "x,y")
+	if got, err := p.GetStyled("list", StyleForm, false); err != nil || !reflect.DeepEqual(got, []string{"x", "y"}) {
+		t.Errorf("Params.GetStyled() of a modified param = %q, %v, want [x y]", got, err)
+	}
+}
+
+func TestParams_StyledObject(t *testing.T) {
+	color := []Param{{Key: "R", Value: "100"}, {Key: "G", Value: "200"}, {Key: "B", Value: "1,5"}}
+	tests := []struct {
+		name       string
+		style      Style
+		explode    bool
+		want       string
+		wantErr    error
+		wantGetErr error
+	}{
+		{name: "Form exploded", style: StyleForm, explode: true, want: "x=1&color%5BR%5D=old&color=old&R=100&G=200&B=1%2C5", wantGetErr: ErrUnsupportedStyle},
+		{name: "Form", style: StyleForm, want: "x=1&color%5BR%5D=old&color=R,100,G,200,B,1%2C5&R=old"},
+		{name: "Space delimited", style: StyleSpaceDelimited, want: "x=1&color%5BR%5D=old&color=R%20100%20G%20200%20B%201%2C5&R=old"},
+		{name: "Pipe delimited", style: StylePipeDelimited, want: "x=1&color%5BR%5D=old&color=R|100|G|200|B|1%2C5&R=old"},
+		{name: "Deep object", style: StyleDeepObject, explode: true, want: "x=1&color[R]=100&color[G]=200&color[B]=1%2C5&color=old&R=old"},
+		{name: "Deep object not exploded", style: StyleDeepObject, wantErr: ErrUnsupportedStyle},
+		{name: "Pipe delimited exploded", style: StylePipeDelimited, explode: true, wantErr: ErrUnsupportedStyle},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Params{{Key: "x", Value: "1"}, {Key: "color[R]", Value: "old"}, {Key: "color", Value: "old"}, {Key: "R", Value: "old"}}
+			err := p.SetStyledObject("color", color, tt.style, tt.explode)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Params.SetStyledObject() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := p.Encode(); got != tt.want {
+				t.Errorf("Params.SetStyledObject() = %q, want %q", got, tt.want)
+			}
+
+			parsed, _ := Parser{KeepRaw: true}.ParseQuery(p.Encode())
+			got, err := parsed.GetStyledObject("color", tt.style, tt.explode)
+			if !errors.Is(err, tt.wantGetErr) {
+				t.Fatalf("Params.GetStyledObject() error = %v, want %v", err, tt.wantGetErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, color) {
+				t.Errorf("Params.GetStyledObject() = %v, want %v", got, color)
+			}
+		})
+	}
+
+	p, _ := Parser{KeepRaw: true}.ParseQuery("color=R,100,G")
+	if _, err := p.GetStyledObject("color", StyleForm, false); !errors.Is(err, ErrMalformedStyled) {
+		t.Errorf("Params.GetStyledObject() error = %v, want ErrMalformedStyled", err)
+	}
+	if got, err := p.GetStyledObject("size", StyleForm, false); got != nil || err != nil {
+		t.Errorf("Params.GetStyledObject() = %v, %v, want nil", got, err)
+	}
+}
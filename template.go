@@ -0,0 +1,347 @@
+package urlqm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// QueryTemplate is a URI template (RFC 6570) whose expressions build a query string:
+// the form-style query expansion `{?x,y}` and the query continuation `{&z}`,
+// with the explode `{?list*}` and the prefix `{?x:3}` modifiers.
+//
+// A template may start with a literal part before the query, like a path, e.g. `/orders{?page,size}`,
+// and it may contain literal params, e.g. `/search?fixed=yes{&q,lang}`. Other expressions, like `{id}` or `{/path}`,
+// are not supported. Values are escaped with [RFC3986Escaper], as the query expressions require.
+//
+// As RFC 6570 defines, only a literal '?' or a `{?...}` expression starts the query. A `{&...}` expression
+// continues a query that is already there, so `{&z}` expands to `&z=1`, and so does `/x{?a}{&z}` if `a` is undefined.
+// Unlike RFC 6570, the expressions after the start of the query continue it, so `{?x}{?y}` expands to `?x=1&y=2`.
+type QueryTemplate struct {
+	base  string
+	parts []templatePart
+	// query tells that the template has a literal '?'.
+	query bool
+}
+
+// templatePart is either literal params or a query expression.
+type templatePart struct {
+	params []Param
+	vars   []templateVar
+	// op is the operator of the expression, '?' or '&', it is '&' for literal params.
+	op byte
+}
+
+type templateVar struct {
+	// name is the variable name as it is in the template, key is the decoded name, which is the key of the params.
+	name, key string
+	prefix    int
+	explode   bool
+}
+
+// ParseQueryTemplate parses a URI template with query expressions. See [QueryTemplate].
+// A malformed template or an unsupported expression returns [ErrInvalidTemplate].
+func ParseQueryTemplate(template string) (*QueryTemplate, error) {
+	t := &QueryTemplate{}
+	inQuery := false
+	for rest := template; rest != ""; {
+		literal := rest
+		open := strings.IndexByte(rest, '{')
+		if open >= 0 {
+			literal = rest[:open]
+		}
+		if strings.IndexByte(literal, '}') >= 0 {
+			return nil, fmt.Errorf("%w: unexpected '}' in %q", ErrInvalidTemplate, template)
+		}
+		if !inQuery {
+			q := strings.IndexByte(literal, '?')
+			if q < 0 {
+				t.base += literal
+				literal = ""
+			} else {
+				t.base += literal[:q]
+				literal = literal[q+1:]
+				inQuery = true
+				t.query = true
+			}
+		}
+		if literal != "" {
+			params, err := Parser{KeepRaw: true}.ParseQuery(literal)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+			}
+			// the separators around the literal params are put by the expansion.
+			params[0].raw.lead = false
+			params[len(params)-1].raw.trail = ""
+			t.parts = append(t.parts, templatePart{params: params, op: '&'})
+		}
+		if open < 0 {
+			break
+		}
+
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unclosed expression in %q", ErrInvalidTemplate, template)
+		}
+		vars, err := parseTemplateExpr(rest[open+1 : open+end])
+		if err != nil {
+			return nil, err
+		}
+		t.parts = append(t.parts, templatePart{vars: vars, op: rest[open+1]})
+		inQuery = true
+		rest = rest[open+end+1:]
+	}
+	return t, nil
+}
+
+// parseTemplateExpr parses the expression between the braces.
+func parseTemplateExpr(expr string) ([]templateVar, error) {
+	if expr == "" || expr[0] != '?' && expr[0] != '&' {
+		return nil, fmt.Errorf("%w: unsupported expression {%s}, only {?...} and {&...} are supported",
+			ErrInvalidTemplate, expr)
+	}
+	specs := strings.Split(expr[1:], ",")
+	vars := make([]templateVar, 0, len(specs))
+	for _, spec := range specs {
+		var v templateVar
+		if strings.HasSuffix(spec, "*") {
+			v.explode = true
+			spec = spec[:len(spec)-1]
+		} else if i := strings.IndexByte(spec, ':'); i >= 0 {
+			n, err := strconv.Atoi(spec[i+1:])
+			if err != nil || n < 1 || n > 9999 || spec[i+1] < '1' || spec[i+1] > '9' {
+				return nil, fmt.Errorf("%w: invalid prefix in {%s}", ErrInvalidTemplate, expr)
+			}
+			v.prefix = n
+			spec = spec[:i]
+		}
+		if !isVarname(spec) {
+			return nil, fmt.Errorf("%w: invalid variable name %q in {%s}", ErrInvalidTemplate, spec, expr)
+		}
+		v.name = spec
+		v.key, _ = unescape(spec, false)
+		vars = append(vars, v)
+	}
+	return vars, nil
+}
+
+// isVarname reports whether s is a variable name of RFC 6570: letters, digits, '_' and escape sequences,
+// which may be separated by single dots.
+func isVarname(s string) bool {
+	if s == "" || s[0] == '.' || s[len(s)-1] == '.' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '_':
+		case c == '.':
+			if s[i-1] == '.' {
+				return false
+			}
+		case c == '%':
+			if i+2 >= len(s) || !ishex(s[i+1]) || !ishex(s[i+2]) {
+				return false
+			}
+			i += 2
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Expand returns the params of the template with the variables filled in, in the order of the template.
+//
+// A variable value is a string, a []string (a list) or a []Param (an associative array, whose order is kept);
+// a missing or nil variable, an empty list and an empty associative array are undefined and expand to nothing.
+// As RFC 6570 defines, a list expands to `x=a,b`, or to `x=a&x=b` if exploded,
+// and an associative array expands to `x=k1,v1,k2,v2`, or to `k1=v1&k2=v2` if exploded.
+// The params keep their escaped form, so the literal commas are not escaped by [Params.Encode].
+//
+// A value of another type, or a prefix modifier of a list or an associative array, returns [ErrTemplateValue].
+func (t *QueryTemplate) Expand(vars map[string]any) (Params, error) {
+	params, _, err := t.expand(vars)
+	return params, err
+}
+
+// expand returns the params of the template and the character that precedes them in the URI:
+// '?' if the query is started by the template, or '&' if it is continued.
+func (t *QueryTemplate) expand(vars map[string]any) (Params, byte, error) {
+	var params Params
+	var op byte
+	if t.query {
+		op = '?'
+	}
+	for _, part := range t.parts {
+		n := len(params)
+		if part.vars == nil {
+			params = append(params, part.params...)
+		}
+		for _, v := range part.vars {
+			var err error
+			if params, err = v.expand(params, vars[v.name]); err != nil {
+				return nil, 0, err
+			}
+		}
+		if op == 0 && len(params) > n {
+			op = part.op
+		}
+	}
+	return params, op, nil
+}
+
+// ExpandQuery returns the query string of the template with the variables filled in,
+// without the leading '?' or '&'. See [QueryTemplate.Expand].
+func (t *QueryTemplate) ExpandQuery(vars map[string]any) (string, error) {
+	params, err := t.Expand(vars)
+	if err != nil {
+		return "", err
+	}
+	return params.Encode(), nil
+}
+
+// ExpandURI returns the whole template with the variables filled in, e.g. `/orders?page=2` for `/orders{?page,size}`.
+// The query string is preceded by '?', or by '&' if the template only continues a query, like `/x{&z}`,
+// and neither is added if the query string is empty. See [QueryTemplate.Expand].
+func (t *QueryTemplate) ExpandURI(vars map[string]any) (string, error) {
+	params, op, err := t.expand(vars)
+	if err != nil || len(params) == 0 {
+		return t.base, err
+	}
+	return t.base + string(op) + params.Encode(), nil
+}
+
+func (v templateVar) expand(params Params, value any) (Params, error) {
+	escape := RFC3986Escaper.Escape
+	switch value := value.(type) {
+	case nil:
+		return params, nil
+	case string:
+		if v.prefix > 0 {
+			value = prefixRunes(value, v.prefix)
+		}
+		return append(params, newRawParam(v.key, value, v.name, escape(value))), nil
+	case []string:
+		if v.prefix > 0 {
+			return nil, fmt.Errorf("%w: prefix of list %q", ErrTemplateValue, v.name)
+		}
+		if len(value) == 0 {
+			return params, nil
+		}
+		if v.explode {
+			for _, item := range value {
+				params = append(params, newRawParam(v.key, item, v.name, escape(item)))
+			}
+			return params, nil
+		}
+		raw := make([]string, len(value))
+		for i, item := range value {
+			raw[i] = escape(item)
+		}
+		return append(params, newRawParam(v.key, strings.Join(value, ","), v.name, strings.Join(raw, ","))), nil
+	case []Param:
+		if v.prefix > 0 {
+			return nil, fmt.Errorf("%w: prefix of associative array %q", ErrTemplateValue, v.name)
+		}
+		if len(value) == 0 {
+			return params, nil
+		}
+		if v.explode {
+			for _, f := range value {
+				params = append(params, newRawParam(f.Key, f.Value, escape(f.Key), escape(f.Value)))
+			}
+			return params, nil
+		}
+		items := make([]string, 0, len(value)*2)
+		raw := make([]string, 0, len(value)*2)
+		for _, f := range value {
+			items = append(items, f.Key, f.Value)
+			raw = append(raw, escape(f.Key), escape(f.Value))
+		}
+		return append(params, newRawParam(v.key, strings.Join(items, ","), v.name, strings.Join(raw, ","))), nil
+	}
+	return nil, fmt.Errorf("%w: %T of %q", ErrTemplateValue, value, v.name)
+}
+
+// prefixRunes returns the first n characters of s.
+func prefixRunes(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
+
+// Extract returns the variables of the template from a query string, the reverse of [QueryTemplate.ExpandQuery].
+//
+// A variable gets the value of the param with its name: a string, or a []string if the value has literal commas,
+// like `x=a,b`, so the escaped commas stay inside the items. An exploded variable gets all the values
+// of its name as a []string, or, if there are none, the params that no other variable or literal param of the template
+// claims, as a []Param. Only the first such variable gets them. The variables without params are not in the map.
+// Since a non-exploded associative array can't be told apart from a list, it is extracted as a []string.
+//
+// The query is parsed like [ParseParams] does, and the parse errors are returned along with the variables.
+func (t *QueryTemplate) Extract(query string) (map[string]any, error) {
+	params, parseErr := Parser{KeepRaw: true}.ParseQuery(query)
+
+	claimed := make(map[string]bool)
+	for _, part := range t.parts {
+		for _, param := range part.params {
+			claimed[param.Key] = true
+		}
+		for _, v := range part.vars {
+			claimed[v.key] = true
+		}
+	}
+
+	vars := make(map[string]any)
+	restTaken := false
+	for _, part := range t.parts {
+		for _, v := range part.vars {
+			if _, ok := vars[v.name]; ok {
+				continue
+			}
+			if v.explode {
+				if values := params.GetAll(v.key); len(values) > 0 {
+					vars[v.name] = values
+					continue
+				}
+				if restTaken {
+					continue
+				}
+				restTaken = true
+				var rest []Param
+				for _, param := range params {
+					if !claimed[param.Key] {
+						rest = append(rest, Param{Key: param.Key, Value: param.Value, Flag: param.Flag})
+					}
+				}
+				if len(rest) > 0 {
+					vars[v.name] = rest
+				}
+				continue
+			}
+			for _, param := range params {
+				if param.Key != v.key {
+					continue
+				}
+				items := []string{param.Value}
+				if hasRawValue(param) {
+					if split, err := splitStyled(param, StyleForm); err == nil {
+						items = split
+					}
+				}
+				if len(items) == 1 {
+					vars[v.name] = items[0]
+				} else {
+					vars[v.name] = items
+				}
+				break
+			}
+		}
+	}
+	return vars, parseErr
+}
@@ -0,0 +1,281 @@
+package urlqm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// rfc6570Vars are the variables of the examples of RFC 6570, section 3.2.
+var rfc6570Vars = map[string]any{
+	"var":   "value",
+	"hello": "Hello World!",
+	"half":  "50%",
+	"empty": "",
+	"who":   "fred",
+	"x":     "1024",
+	"y":     "768",
+	"list":  []string{"red", "green", "blue"},
+	"keys":  []Param{{Key: "semi", Value: ";"}, {Key: "dot", Value: "."}, {Key: "comma", Value: ","}},
+	"undef": nil,
+}
+
+func TestQueryTemplate_Expand(t *testing.T) {
+	tests := []struct {
+		template string
+		vars     map[string]any
+		want     string
+	}{
+		// RFC 6570, section 3.2.8.
+		{template: "{?who}", want: "?who=fred"},
+		{template: "{?half}", want: "?half=50%25"},
+		{template: "{?x,y}", want: "?x=1024&y=768"},
+		{template: "{?x,y,empty}", want: "?x=1024&y=768&empty="},
+		{template: "{?x,y,undef}", want: "?x=1024&y=768"},
+		{template: "{?var:3}", want: "?var=val"},
+		{template: "{?list}", want: "?list=red,green,blue"},
+		{template: "{?list*}", want: "?list=red&list=green&list=blue"},
+		{template: "{?keys}", want: "?keys=semi,%3B,dot,.,comma,%2C"},
+		{template: "{?keys*}", want: "?semi=%3B&dot=.&comma=%2C"},
+		// RFC 6570, section 3.2.9.
+		{template: "{&who}", want: "&who=fred"},
+		{template: "{&half}", want: "&half=50%25"},
+		{template: "?fixed=yes{&x}", want: "?fixed=yes&x=1024"},
+		{template: "{&x,y,empty}", want: "&x=1024&y=768&empty="},
+		{template: "{&var:3}", want: "&var=val"},
+		{template: "{&list}", want: "&list=red,green,blue"},
+		{template: "{&list*}", want: "&list=red&list=green&list=blue"},
+		{template: "{&keys}", want: "&keys=semi,%3B,dot,.,comma,%2C"},
+		{template: "{&keys*}", want: "&semi=%3B&dot=.&comma=%2C"},
+		// RFC 6570, section 1.2.
+		{template: "{?hello}", want: "?hello=Hello%20World%21"},
+		{template: "{?x,hello,y}", want: "?x=1024&hello=Hello%20World%21&y=768"},
+
+		{template: "", want: ""},
+		{template: "/orders{?undef,empty}", want: "/orders?empty="},
+		{template: "/orders{?undef}", want: "/orders"},
+		{template: "/orders?", want: "/orders"},
+		{template: "/search?fixed=yes&b=%7e{&who}&c=1{&x}", want: "/search?fixed=yes&b=%7e&who=fred&c=1&x=1024"},
+		{template: "/search{?who}{&x,y}", want: "/search?who=fred&x=1024&y=768"},
+		{template: "/search{?undef}{&x,y}", want: "/search&x=1024&y=768"},
+		{template: "/search{&undef}{?x}{&y}", want: "/search?x=1024&y=768"},
+		{template: "/search?{&x}", want: "/search?x=1024"},
+		{template: "/search{?x}{?y}", want: "/search?x=1024&y=768"},
+		{template: "/search{?undef}&a=1{&x}", want: "/search&a=1&x=1024"},
+		{template: "{?hello:5}", want: "?hello=Hello"},
+		{template: "{?hello:50}", want: "?hello=Hello%20World%21"},
+		{template: "{?a.b,c%20d}", vars: map[string]any{"a.b": "1", "c%20d": "2"}, want: "?a.b=1&c%20d=2"},
+		{template: "{?s:2}", vars: map[string]any{"s": "ключ"}, want: "?s=%D0%BA%D0%BB"},
+		{template: "{?l,k*}", vars: map[string]any{"l": []string{}, "k": []Param{}}, want: ""},
+		{template: "{?l}", vars: map[string]any{"l": []string{"a,b", "c d"}}, want: "?l=a%2Cb,c%20d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			tmpl, err := ParseQueryTemplate(tt.template)
+			if err != nil {
+				t.Fatalf("ParseQueryTemplate() error = %v", err)
+			}
+			vars := tt.vars
+			if vars == nil {
+				vars = rfc6570Vars
+			}
+			got, err := tmpl.ExpandURI(vars)
+			if err != nil {
+				t.Fatalf("ExpandURI() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandURI() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryTemplate_ExpandParams(t *testing.T) {
+	tmpl, err := ParseQueryTemplate("/search?fixed=yes{&list,keys*}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := tmpl.Expand(rfc6570Vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Params{
+		{Key: "fixed", Value: "yes"},
+		{Key: "list", Value: "red,green,blue"},
+		{Key: "semi", Value: ";"},
+		{Key: "dot", Value: "."},
+		{Key: "comma", Value: ","},
+	}
+	if len(params) != len(want) {
+		t.Fatalf("Expand() = %v, want %v", params, want)
+	}
+	for i := range want {
+		if params[i].Key != want[i].Key || params[i].Value != want[i].Value || params[i].Flag {
+			t.Errorf("Expand()[%d] = %v, want %v", i, params[i], want[i])
+		}
+	}
+
+	params.Set("page", "2")
+	query, err := tmpl.ExpandQuery(rfc6570Vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := params.Encode(); got != query+"&page=2" {
+		t.Errorf("Encode() = %q, want %q", got, query+"&page=2")
+	}
+}
+
+func TestParseQueryTemplate_Errors(t *testing.T) {
+	templates := []string{
+		"/orders/{id}{?page}",
+		"{/path}",
+		"{#frag}",
+		"{?}",
+		"{?a,}",
+		"{?a b}",
+		"{?.a}",
+		"{?a..b}",
+		"{?a%2}",
+		"{?a:0}",
+		"{?a:10000}",
+		"{?a:x}",
+		"{?a:01}",
+		"{?a",
+		"?a}",
+		"{}",
+		"?a=%zz{&b}",
+	}
+	for _, template := range templates {
+		t.Run(template, func(t *testing.T) {
+			if _, err := ParseQueryTemplate(template); !errors.Is(err, ErrInvalidTemplate) {
+				t.Errorf("ParseQueryTemplate() error = %v, want %v", err, ErrInvalidTemplate)
+			}
+		})
+	}
+}
+
+func TestQueryTemplate_ExpandErrors(t *testing.T) {
+	tests := []struct {
+		template string
+		vars     map[string]any
+	}{
+		{template: "{?n}", vars: map[string]any{"n": 1}},
+		{template: "{?m}", vars: map[string]any{"m": map[string]string{"a": "b"}}},
+		{template: "{?list:2}", vars: rfc6570Vars},
+		{template: "{?keys:2}", vars: rfc6570Vars},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			tmpl, err := ParseQueryTemplate(tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tmpl.ExpandURI(tt.vars); !errors.Is(err, ErrTemplateValue) {
+				t.Errorf("ExpandURI() error = %v, want %v", err, ErrTemplateValue)
+			}
+		})
+	}
+}
+
+func TestQueryTemplate_Extract(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		query    string
+		want     map[string]any
+	}{
+		{
+			name:     "Strings",
+			template: "{?who,hello,empty,undef}",
+			query:    "who=fred&hello=Hello%20World%21&empty=",
+			want:     map[string]any{"who": "fred", "hello": "Hello World!", "empty": ""},
+		},
+		{
+			name:     "List",
+			template: "{?list}",
+			query:    "list=red,green,blue",
+			want:     map[string]any{"list": []string{"red", "green", "blue"}},
+		},
+		{
+			name:     "Escaped commas",
+			template: "{?l,s}",
+			query:    "l=a%2Cb,c%20d&s=a%2Cb",
+			want:     map[string]any{"l": []string{"a,b", "c d"}, "s": "a,b"},
+		},
+		{
+			name:     "Exploded list",
+			template: "{?list*}",
+			query:    "list=red&list=green&list=blue",
+			want:     map[string]any{"list": []string{"red", "green", "blue"}},
+		},
+		{
+			name:     "Exploded associative array",
+			template: "?fixed=yes{&who,keys*,other*}",
+			query:    "fixed=yes&who=fred&semi=%3B&dot=.&comma=%2C",
+			want: map[string]any{
+				"who":  "fred",
+				"keys": []Param{{Key: "semi", Value: ";"}, {Key: "dot", Value: "."}, {Key: "comma", Value: ","}},
+			},
+		},
+		{
+			name:     "Flag",
+			template: "{?debug}",
+			query:    "debug",
+			want:     map[string]any{"debug": ""},
+		},
+		{
+			name:     "Empty query",
+			template: "{?who,list*}",
+			query:    "",
+			want:     map[string]any{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseQueryTemplate(tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := tmpl.Extract(tt.query)
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryTemplate_RoundTrip(t *testing.T) {
+	templates := []string{
+		"{?who,hello,empty}",
+		"{?x,y}{&list}",
+		"{?list*}",
+		"?fixed=yes{&keys*}",
+		"/search{?half,list,keys*}",
+	}
+	for _, template := range templates {
+		t.Run(template, func(t *testing.T) {
+			tmpl, err := ParseQueryTemplate(template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query, err := tmpl.ExpandQuery(rfc6570Vars)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vars, err := tmpl.Extract(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := tmpl.ExpandQuery(vars)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again != query {
+				t.Errorf("ExpandQuery(Extract(%q)) = %q", query, again)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package test
 
 import (
+	"fmt"
 	"net/url"
+	"strconv"
 	"testing"
 
 	"github.com/niklak/urlqm"
@@ -64,6 +66,20 @@ func BenchmarkGetQueryParamOneStd(b *testing.B) {
 	}
 }
 
+func BenchmarkGetQueryParamOneScanner(b *testing.B) {
+	query := simpleRawQuery
+	buf := make([]byte, 0, 64)
+	for i := 0; i < b.N; i++ {
+		s := urlqm.NewQueryScanner(query)
+		for s.Next() {
+			if s.RawKey() == "uuid" {
+				buf, _ = s.AppendValue(buf[:0])
+				break
+			}
+		}
+	}
+}
+
 func BenchmarkGetQueryParamAll(b *testing.B) {
 	query := simpleRawQuery
 	for i := 0; i < b.N; i++ {
@@ -99,6 +115,18 @@ func BenchmarkParseParamsUrlP(b *testing.B) {
 	}
 }
 
+func BenchmarkScanParams(b *testing.B) {
+	query := simpleRawQuery
+	buf := make([]byte, 0, 128)
+	for i := 0; i < b.N; i++ {
+		s := urlqm.NewQueryScanner(query)
+		for s.Next() {
+			buf, _ = s.AppendKey(buf[:0])
+			buf, _ = s.AppendValue(buf[:0])
+		}
+	}
+}
+
 func BenchmarkEncodeParamsStd(b *testing.B) {
 	// std query parse and encode breaks original query param order
 	q, _ := url.ParseQuery(simpleRawQuery)
@@ -331,3 +359,146 @@ func BenchmarkHasQueryParamStd(b *testing.B) {
 		q.Has("uuid")
 	}
 }
+
+// paramsOfSize returns params with n unique keys, the looked up key is the last one.
+func paramsOfSize(n int) urlqm.Params {
+	params := make(urlqm.Params, 0, n)
+	for i := 0; i < n; i++ {
+		params = append(params, urlqm.Param{Key: "key" + strconv.Itoa(i), Value: "value"})
+	}
+	return params
+}
+
+var paramListSizes = []int{5, 10, 25, 100, 1000}
+
+func BenchmarkLookupParams(b *testing.B) {
+	for _, n := range paramListSizes {
+		params := paramsOfSize(n)
+		key := params[n-1].Key
+		b.Run(fmt.Sprintf("UrlP-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				params.Lookup(key)
+			}
+		})
+		indexed := urlqm.NewIndexedParams(paramsOfSize(n))
+		b.Run(fmt.Sprintf("Indexed-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				indexed.Lookup(key)
+			}
+		})
+	}
+}
+
+// BenchmarkIndexParamsLookups includes the cost of building the index, followed by n lookups of different keys,
+// so it shows the list size from which IndexedParams pays off.
+func BenchmarkIndexParamsLookups(b *testing.B) {
+	for _, n := range paramListSizes {
+		params := paramsOfSize(n)
+		b.Run(fmt.Sprintf("UrlP-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, param := range params {
+					params.Has(param.Key)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Indexed-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				indexed := urlqm.NewIndexedParams(params)
+				for _, param := range params {
+					indexed.Has(param.Key)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSetParamsExisting(b *testing.B) {
+	for _, n := range paramListSizes {
+		params := paramsOfSize(n)
+		key := params[n-1].Key
+		b.Run(fmt.Sprintf("UrlP-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				params.Set(key, "new+value")
+			}
+		})
+		indexed := urlqm.NewIndexedParams(paramsOfSize(n))
+		b.Run(fmt.Sprintf("Indexed-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				indexed.Set(key, "new+value")
+			}
+		})
+	}
+}
+
+const trackingRawQuery = "utm_source=newsletter&utm_medium=email&utm_campaign=spring_sale&q=a+testing+query" +
+	"&page=2&fbclid=IwAR2F4-dbP0l7Mn1IawQQGCINEz7PYXQvwjNwB_qa2ofrHyiLjcbCRxTDMgk&gclid=EAIaIQobChMI&sort=date"
+
+func BenchmarkStripTracking(b *testing.B) {
+	var query string
+	for i := 0; i < b.N; i++ {
+		query = trackingRawQuery
+		urlqm.StripTracking(&query)
+	}
+}
+
+func BenchmarkStripTrackingDeleteAll(b *testing.B) {
+	keys := []string{"utm_source", "utm_medium", "utm_campaign", "fbclid", "gclid"}
+	var query string
+	for i := 0; i < b.N; i++ {
+		query = trackingRawQuery
+		for _, key := range keys {
+			urlqm.DeleteQueryParamAll(&query, key)
+		}
+	}
+}
+
+func BenchmarkDeleteParams(b *testing.B) {
+	var query string
+	for i := 0; i < b.N; i++ {
+		query = trackingRawQuery
+		urlqm.DeleteQueryParams(&query, "utm_source", "utm_medium", "utm_campaign", "fbclid", "gclid")
+	}
+}
+
+func BenchmarkKeepParams(b *testing.B) {
+	var query string
+	for i := 0; i < b.N; i++ {
+		query = trackingRawQuery
+		urlqm.KeepQueryParams(&query, "q", "page", "sort")
+	}
+}
+
+func BenchmarkDeleteParamsFuncGlob(b *testing.B) {
+	match := urlqm.GlobRule("utm_*", "*clid").Match
+	var query string
+	for i := 0; i < b.N; i++ {
+		query = trackingRawQuery
+		urlqm.DeleteQueryParamsFunc(&query, match)
+	}
+}
+
+// BenchmarkDecodeNested reports the time per param, which stays flat as the number of params grows.
+func BenchmarkDecodeNested(b *testing.B) {
+	shapes := map[string]func(i int) urlqm.Param{
+		"Keys":     func(i int) urlqm.Param { return urlqm.Param{Key: "k" + strconv.Itoa(i) + "[x]", Value: "1"} },
+		"Appended": func(i int) urlqm.Param { return urlqm.Param{Key: "a[]", Value: "x"} },
+		"Repeated": func(i int) urlqm.Param { return urlqm.Param{Key: "a", Value: "x"} },
+	}
+	for _, name := range []string{"Keys", "Appended", "Repeated"} {
+		for _, n := range []int{1000, 10000, 40000} {
+			params := make(urlqm.Params, n)
+			for i := range params {
+				params[i] = shapes[name](i)
+			}
+			b.Run(fmt.Sprintf("%s-%d", name, n), func(b *testing.B) {
+				var np urlqm.NestedParser
+				for i := 0; i < b.N; i++ {
+					if _, err := np.Decode(params); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/param")
+			})
+		}
+	}
+}
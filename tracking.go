@@ -0,0 +1,188 @@
+package urlqm
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rule matches the keys of params, like the keys of tracking params for [StripTracking].
+type Rule interface {
+	Match(key string) bool
+}
+
+// RuleFunc is a function that matches keys as a [Rule].
+type RuleFunc func(key string) bool
+
+// Match returns f(key).
+func (f RuleFunc) Match(key string) bool {
+	return f(key)
+}
+
+// Rules is a set of rules, it matches a key if any of the rules does.
+type Rules []Rule
+
+// Match reports whether any of the rules matches the key.
+func (r Rules) Match(key string) bool {
+	for _, rule := range r {
+		if rule.Match(key) {
+			return true
+		}
+	}
+	return false
+}
+
+type exactRule map[string]struct{}
+
+func (r exactRule) Match(key string) bool {
+	_, ok := r[key]
+	return ok
+}
+
+// ExactRule returns a [Rule] that matches the given keys exactly.
+func ExactRule(keys ...string) Rule {
+	r := make(exactRule, len(keys))
+	for _, key := range keys {
+		r[key] = struct{}{}
+	}
+	return r
+}
+
+type prefixRule []string
+
+func (r prefixRule) Match(key string) bool {
+	for _, prefix := range r {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// PrefixRule returns a [Rule] that matches the keys starting with any of the given prefixes.
+func PrefixRule(prefixes ...string) Rule {
+	return prefixRule(prefixes)
+}
+
+type globRule []string
+
+func (r globRule) Match(key string) bool {
+	for _, pattern := range r {
+		if globMatch(pattern, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// GlobRule returns a [Rule] that matches the keys matched by any of the given glob patterns.
+// In a pattern, '*' matches any sequence of characters, '?' matches a single character,
+// and the other characters match themselves, e.g. "utm_*" or "*_id".
+func GlobRule(patterns ...string) Rule {
+	return globRule(patterns)
+}
+
+// globMatch reports whether s matches the glob pattern. On a mismatch, the last '*' is retried
+// with one more character of s, so the earlier '*'s never have to be retried.
+func globMatch(pattern, s string) bool {
+	px, sx := 0, 0
+	// nextPx and nextSx are where to restart after a mismatch: the last '*' and one character further in s.
+	nextPx, nextSx := 0, 0
+	for px < len(pattern) || sx < len(s) {
+		if px < len(pattern) {
+			switch c := pattern[px]; c {
+			case '*':
+				nextPx, nextSx = px, sx+runeLen(s[sx:])
+				px++
+				continue
+			case '?':
+				if sx < len(s) {
+					px++
+					sx += runeLen(s[sx:])
+					continue
+				}
+			default:
+				if sx < len(s) && s[sx] == c {
+					px++
+					sx++
+					continue
+				}
+			}
+		}
+		if 0 < nextSx && nextSx <= len(s) {
+			px, sx = nextPx, nextSx
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// runeLen returns the length of the first character of s, or 1 if s is empty.
+func runeLen(s string) int {
+	if s == "" {
+		return 1
+	}
+	_, size := utf8.DecodeRuneInString(s)
+	return size
+}
+
+// RegexpRule returns a [Rule] that matches the keys matched by the regular expression.
+// Anchor the expression with `^` and `$` to match whole keys.
+func RegexpRule(re *regexp.Regexp) Rule {
+	return RuleFunc(re.MatchString)
+}
+
+// TrackingRules are the built-in rules of [StripTracking]. They match the params added to links
+// by analytics and advertising platforms: `utm_*` campaign params, click identifiers like
+// fbclid, gclid, msclkid and yclid, and the params of Mailchimp, HubSpot, Marketo, Matomo and others.
+var TrackingRules Rule = Rules{
+	PrefixRule("utm_", "mtm_", "hsa_"),
+	ExactRule(
+		// Google
+		"gclid", "gclsrc", "dclid", "gbraid", "wbraid", "_ga", "_gl", "srsltid",
+		// Meta
+		"fbclid", "igshid",
+		// Microsoft
+		"msclkid",
+		// Yandex
+		"yclid", "ysclid",
+		// Twitter, TikTok, LinkedIn
+		"twclid", "ttclid", "li_fat_id",
+		// Mailchimp
+		"mc_eid", "mc_cid",
+		// HubSpot
+		"_hsenc", "_hsmi", "__hssc", "__hstc", "__hsfp", "hsCtaTracking",
+		// Marketo, Olytics, Vero, Pinterest, others
+		"mkt_tok", "oly_anon_id", "oly_enc_id", "vero_id", "vero_conv", "epik", "rb_clickid", "s_cid", "wickedid",
+		// Matomo, Piwik
+		"pk_campaign", "pk_kwd", "pk_keyword", "pk_source", "pk_medium", "pk_content",
+	),
+}
+
+// StripTracking removes the tracking params matched by [TrackingRules], and by any of the given rules,
+// from the query string. The query string is rewritten in a single pass, only if something was removed.
+//
+//	StripTracking(&u.RawQuery, ExactRule("ref"), PrefixRule("ga_"))
+func StripTracking(query *string, rules ...Rule) {
+	defaultParser.StripTracking(query, rules...)
+}
+
+// StripTracking removes the tracking params from the query string. See [StripTracking].
+func (p Parser) StripTracking(query *string, rules ...Rule) {
+	p.deleteQueryParamsFunc(query, trackingRules(rules).Match)
+}
+
+// StripTracking removes the tracking params matched by [TrackingRules], and by any of the given rules,
+// from the params slice.
+func (p *Params) StripTracking(rules ...Rule) {
+	match := trackingRules(rules)
+	p.DeleteFunc(match.Match)
+}
+
+func trackingRules(rules []Rule) Rule {
+	if len(rules) == 0 {
+		return TrackingRules
+	}
+	return append(Rules{TrackingRules}, rules...)
+}
@@ -0,0 +1,121 @@
+package urlqm
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestStripTracking(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser Parser
+		query  string
+		rules  []Rule
+		want   string
+	}{
+		{
+			name:  "Empty",
+			query: "",
+			want:  "",
+		},
+		{
+			name:  "Nothing to strip",
+			query: "q=go&page=2",
+			want:  "q=go&page=2",
+		},
+		{
+			name:  "Built-in rules",
+			query: "utm_source=news&q=go&fbclid=abc;gclid=1&page=2&utm_medium=email&msclkid&_ga=2.1&yclid=5",
+			want:  "q=go&page=2",
+		},
+		{
+			name:  "Only tracking params",
+			query: "utm_source=news&mc_eid=1",
+			want:  "",
+		},
+		{
+			name:  "Whole keys only",
+			query: "xutm_source=1&gclid_x=2&my_fbclid=3&utm=4",
+			want:  "xutm_source=1&gclid_x=2&my_fbclid=3&utm=4",
+		},
+		{
+			name:  "Custom rules",
+			query: "ref=home&q=go&ga_id=1&sessionid=x&page=2&ref_src=tw",
+			rules: []Rule{ExactRule("ref"), PrefixRule("ga_"), RegexpRule(regexp.MustCompile(`^(session|ref_)`))},
+			want:  "q=go&page=2",
+		},
+		{
+			name:   "Decoded keys",
+			parser: Parser{DecodeKeys: true},
+			query:  "utm%5Fsource=1&q=go",
+			want:   "q=go",
+		},
+		{
+			name:   "Custom separators",
+			parser: Parser{Separators: "&"},
+			query:  "q=a;utm_source=1&utm_source=2",
+			want:   "q=a;utm_source=1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			tt.parser.StripTracking(&query, tt.rules...)
+			if query != tt.want {
+				t.Errorf("Parser.StripTracking() = %q, want %q", query, tt.want)
+			}
+		})
+	}
+
+	query := "utm_source=1&a=1"
+	StripTracking(&query)
+	if query != "a=1" {
+		t.Errorf("StripTracking() = %q, want %q", query, "a=1")
+	}
+}
+
+func TestParams_StripTracking(t *testing.T) {
+	p := Params{
+		{Key: "utm_source", Value: "news"}, {Key: "q", Value: "go"}, {Key: "fbclid", Value: "x"},
+		{Key: "fbclid", Value: "y"}, {Key: "ref", Value: "home"}, {Key: "page", Value: "2"},
+	}
+	p.StripTracking(RuleFunc(func(key string) bool { return key == "ref" }))
+	want := Params{{Key: "q", Value: "go"}, {Key: "page", Value: "2"}}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("Params.StripTracking() = %v, want %v", p, want)
+	}
+}
+
+func TestGlobRule(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		key      string
+		want     bool
+	}{
+		{patterns: []string{"utm_*"}, key: "utm_source", want: true},
+		{patterns: []string{"utm_*"}, key: "utm_", want: true},
+		{patterns: []string{"utm_*"}, key: "xutm_source"},
+		{patterns: []string{"*_id"}, key: "session_id", want: true},
+		{patterns: []string{"*_id"}, key: "session_idx"},
+		{patterns: []string{"a*b*c"}, key: "abbbcbc", want: true},
+		{patterns: []string{"a*b*c"}, key: "abcb"},
+		{patterns: []string{"v?"}, key: "v1", want: true},
+		{patterns: []string{"v?"}, key: "vя", want: true},
+		{patterns: []string{"v?"}, key: "v"},
+		{patterns: []string{"v?"}, key: "v12"},
+		{patterns: []string{"*"}, key: "", want: true},
+		{patterns: []string{"**"}, key: "any", want: true},
+		{patterns: []string{""}, key: "a"},
+		{patterns: []string{""}, key: "", want: true},
+		{patterns: []string{"ref"}, key: "ref", want: true},
+		{patterns: []string{"ref"}, key: "referrer"},
+		{patterns: []string{"fb*", "*clid"}, key: "gclid", want: true},
+		{patterns: nil, key: "gclid"},
+	}
+	for _, tt := range tests {
+		if got := GlobRule(tt.patterns...).Match(tt.key); got != tt.want {
+			t.Errorf("GlobRule(%q).Match(%q) = %v, want %v", tt.patterns, tt.key, got, tt.want)
+		}
+	}
+}
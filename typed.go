@@ -0,0 +1,247 @@
+package urlqm
+
+import (
+	"strconv"
+	"time"
+)
+
+// GetQueryParamInt returns the value of a parameter from the query string as an int,
+// and reports whether the parameter is present. An absent parameter is not an error.
+// A value that is not an int, an empty one included, returns a [*ConversionError],
+// and a value that fails to be decoded returns a [*ParseError].
+// If the given key contains non-ASCII characters, it must be url-encoded before calling this function,
+// or [Parser.DecodeKeys] can be used instead.
+func GetQueryParamInt(query string, key string) (value int, found bool, err error) {
+	return defaultParser.GetQueryParamInt(query, key)
+}
+
+// GetQueryParamBool returns the value of a parameter from the query string as a bool, see [GetQueryParamInt].
+// The value is parsed with [strconv.ParseBool], and a valueless parameter (a bare key) is true.
+func GetQueryParamBool(query string, key string) (value bool, found bool, err error) {
+	return defaultParser.GetQueryParamBool(query, key)
+}
+
+// GetQueryParamFloat returns the value of a parameter from the query string as a float64, see [GetQueryParamInt].
+func GetQueryParamFloat(query string, key string) (value float64, found bool, err error) {
+	return defaultParser.GetQueryParamFloat(query, key)
+}
+
+// GetQueryParamDuration returns the value of a parameter from the query string as a [time.Duration],
+// parsed with [time.ParseDuration], see [GetQueryParamInt].
+func GetQueryParamDuration(query string, key string) (value time.Duration, found bool, err error) {
+	return defaultParser.GetQueryParamDuration(query, key)
+}
+
+// GetQueryParamTime returns the value of a parameter from the query string as a [time.Time],
+// parsed with the layout, or with [time.RFC3339] if the layout is empty, see [GetQueryParamInt].
+func GetQueryParamTime(query string, key string, layout string) (value time.Time, found bool, err error) {
+	return defaultParser.GetQueryParamTime(query, key, layout)
+}
+
+// SetQueryParamInt sets a parameter with an int value in the query string, see [SetQueryParam].
+func SetQueryParamInt(query *string, key string, value int) {
+	defaultParser.SetQueryParamInt(query, key, value)
+}
+
+// SetQueryParamBool sets a parameter with a bool value, "true" or "false", in the query string, see [SetQueryParam].
+func SetQueryParamBool(query *string, key string, value bool) {
+	defaultParser.SetQueryParamBool(query, key, value)
+}
+
+// SetQueryParamFloat sets a parameter with a float64 value in the query string, see [SetQueryParam].
+// The value is formatted without an exponent, in the fewest digits that represent it exactly.
+func SetQueryParamFloat(query *string, key string, value float64) {
+	defaultParser.SetQueryParamFloat(query, key, value)
+}
+
+// SetQueryParamDuration sets a parameter with a [time.Duration] value, like "1h30m0s",
+// in the query string, see [SetQueryParam].
+func SetQueryParamDuration(query *string, key string, value time.Duration) {
+	defaultParser.SetQueryParamDuration(query, key, value)
+}
+
+// SetQueryParamTime sets a parameter with a [time.Time] value, formatted with the layout,
+// or with [time.RFC3339] if the layout is empty, in the query string, see [SetQueryParam].
+func SetQueryParamTime(query *string, key string, value time.Time, layout string) {
+	defaultParser.SetQueryParamTime(query, key, value, layout)
+}
+
+// GetQueryParamInt returns the value of a parameter from the query string as an int. See [GetQueryParamInt].
+func (p Parser) GetQueryParamInt(query string, key string) (int, bool, error) {
+	return convertParam(p.lookupQueryParam(query, key), strconv.Atoi)
+}
+
+// GetQueryParamBool returns the value of a parameter from the query string as a bool. See [GetQueryParamBool].
+func (p Parser) GetQueryParamBool(query string, key string) (bool, bool, error) {
+	return convertBool(p.lookupQueryParam(query, key))
+}
+
+// GetQueryParamFloat returns the value of a parameter from the query string as a float64.
+// See [GetQueryParamFloat].
+func (p Parser) GetQueryParamFloat(query string, key string) (float64, bool, error) {
+	return convertParam(p.lookupQueryParam(query, key), parseFloat)
+}
+
+// GetQueryParamDuration returns the value of a parameter from the query string as a [time.Duration].
+// See [GetQueryParamDuration].
+func (p Parser) GetQueryParamDuration(query string, key string) (time.Duration, bool, error) {
+	return convertParam(p.lookupQueryParam(query, key), time.ParseDuration)
+}
+
+// GetQueryParamTime returns the value of a parameter from the query string as a [time.Time].
+// See [GetQueryParamTime].
+func (p Parser) GetQueryParamTime(query string, key string, layout string) (time.Time, bool, error) {
+	return convertParam(p.lookupQueryParam(query, key), timeParser(layout))
+}
+
+// SetQueryParamInt sets a parameter with an int value in the query string. See [SetQueryParamInt].
+func (p Parser) SetQueryParamInt(query *string, key string, value int) {
+	p.SetQueryParam(query, key, strconv.Itoa(value))
+}
+
+// SetQueryParamBool sets a parameter with a bool value in the query string. See [SetQueryParamBool].
+func (p Parser) SetQueryParamBool(query *string, key string, value bool) {
+	p.SetQueryParam(query, key, strconv.FormatBool(value))
+}
+
+// SetQueryParamFloat sets a parameter with a float64 value in the query string. See [SetQueryParamFloat].
+func (p Parser) SetQueryParamFloat(query *string, key string, value float64) {
+	p.SetQueryParam(query, key, formatFloat(value))
+}
+
+// SetQueryParamDuration sets a parameter with a [time.Duration] value in the query string.
+// See [SetQueryParamDuration].
+func (p Parser) SetQueryParamDuration(query *string, key string, value time.Duration) {
+	p.SetQueryParam(query, key, value.String())
+}
+
+// SetQueryParamTime sets a parameter with a [time.Time] value in the query string. See [SetQueryParamTime].
+func (p Parser) SetQueryParamTime(query *string, key string, value time.Time, layout string) {
+	p.SetQueryParam(query, key, value.Format(timeLayout(layout)))
+}
+
+// GetInt returns the first value of a param with given key as an int, and reports whether the param is present.
+// See [GetQueryParamInt] for the errors.
+func (p *Params) GetInt(key string) (int, bool, error) {
+	return convertParam(p.lookupParam(key), strconv.Atoi)
+}
+
+// GetBool returns the first value of a param with given key as a bool, see [Params.GetInt].
+// The value is parsed with [strconv.ParseBool], and a valueless param (a flag) is true.
+func (p *Params) GetBool(key string) (bool, bool, error) {
+	return convertBool(p.lookupParam(key))
+}
+
+// GetFloat returns the first value of a param with given key as a float64, see [Params.GetInt].
+func (p *Params) GetFloat(key string) (float64, bool, error) {
+	return convertParam(p.lookupParam(key), parseFloat)
+}
+
+// GetDuration returns the first value of a param with given key as a [time.Duration],
+// parsed with [time.ParseDuration], see [Params.GetInt].
+func (p *Params) GetDuration(key string) (time.Duration, bool, error) {
+	return convertParam(p.lookupParam(key), time.ParseDuration)
+}
+
+// GetTime returns the first value of a param with given key as a [time.Time],
+// parsed with the layout, or with [time.RFC3339] if the layout is empty, see [Params.GetInt].
+func (p *Params) GetTime(key string, layout string) (time.Time, bool, error) {
+	return convertParam(p.lookupParam(key), timeParser(layout))
+}
+
+// SetInt sets a param with an int value, see [Params.Set].
+func (p *Params) SetInt(key string, value int) {
+	p.Set(key, strconv.Itoa(value))
+}
+
+// SetBool sets a param with a bool value, "true" or "false", see [Params.Set].
+func (p *Params) SetBool(key string, value bool) {
+	p.Set(key, strconv.FormatBool(value))
+}
+
+// SetFloat sets a param with a float64 value, formatted like [SetQueryParamFloat] does, see [Params.Set].
+func (p *Params) SetFloat(key string, value float64) {
+	p.Set(key, formatFloat(value))
+}
+
+// SetDuration sets a param with a [time.Duration] value, like "1h30m0s", see [Params.Set].
+func (p *Params) SetDuration(key string, value time.Duration) {
+	p.Set(key, value.String())
+}
+
+// SetTime sets a param with a [time.Time] value, formatted with the layout,
+// or with [time.RFC3339] if the layout is empty, see [Params.Set].
+func (p *Params) SetTime(key string, value time.Time, layout string) {
+	p.Set(key, value.Format(timeLayout(layout)))
+}
+
+// lookupResult is a param found by a typed getter.
+type lookupResult struct {
+	key, value  string
+	flag, found bool
+	err         error
+}
+
+// lookupQueryParam finds the first parameter with the key in the query string, see [Parser.LookupQueryParam].
+func (p Parser) lookupQueryParam(query string, key string) lookupResult {
+	value, found, err := p.LookupQueryParam(query, key)
+	r := lookupResult{key: key, value: value, found: found, err: err}
+	if found && value == "" {
+		start, end := p.indexParam(query, key, 0)
+		r.flag = isFlag(query[start:end])
+	}
+	return r
+}
+
+// lookupParam finds the first param with the key, like [Params.Lookup] does.
+func (p *Params) lookupParam(key string) lookupResult {
+	for _, param := range *p {
+		if param.Key == key {
+			return lookupResult{key: key, value: param.Value, flag: param.Flag, found: true}
+		}
+	}
+	return lookupResult{key: key}
+}
+
+// convertParam converts the value of the found param. A failed conversion is returned as a [*ConversionError].
+func convertParam[T any](r lookupResult, convert func(string) (T, error)) (T, bool, error) {
+	var zero T
+	if !r.found || r.err != nil {
+		return zero, r.found, r.err
+	}
+	value, err := convert(r.value)
+	if err != nil {
+		return zero, true, &ConversionError{Key: r.key, Value: r.value, Err: err}
+	}
+	return value, true, nil
+}
+
+// convertBool is like convertParam for a bool, but a valueless param is true.
+func convertBool(r lookupResult) (bool, bool, error) {
+	if r.found && r.flag && r.err == nil {
+		return true, true, nil
+	}
+	return convertParam(r, strconv.ParseBool)
+}
+
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func timeLayout(layout string) string {
+	if layout == "" {
+		return time.RFC3339
+	}
+	return layout
+}
+
+func timeParser(layout string) func(string) (time.Time, error) {
+	layout = timeLayout(layout)
+	return func(s string) (time.Time, error) {
+		return time.Parse(layout, s)
+	}
+}
@@ -0,0 +1,222 @@
+package urlqm
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGetQueryParamTyped(t *testing.T) {
+	query := "page=2&neg=-7&big=99999999999999999999&debug&verbose=false&on=1&ratio=0.25&exp=1e3" +
+		"&ttl=1h30m&since=2024-05-01T10%3A00%3A00%2B02%3A00&day=2024-05-01&empty=&bad=%zz&page=3"
+
+	t.Run("Int", func(t *testing.T) {
+		tests := []struct {
+			key       string
+			want      int
+			wantFound bool
+			wantErr   bool
+		}{
+			{key: "page", want: 2, wantFound: true},
+			{key: "neg", want: -7, wantFound: true},
+			{key: "missing"},
+			{key: "big", wantFound: true, wantErr: true},
+			{key: "ratio", wantFound: true, wantErr: true},
+			{key: "empty", wantFound: true, wantErr: true},
+			{key: "debug", wantFound: true, wantErr: true},
+		}
+		for _, tt := range tests {
+			got, found, err := GetQueryParamInt(query, tt.key)
+			if got != tt.want || found != tt.wantFound || (err != nil) != tt.wantErr {
+				t.Errorf("GetQueryParamInt(%q) = %v, %v, %v, want %v, %v, error %v",
+					tt.key, got, found, err, tt.want, tt.wantFound, tt.wantErr)
+			}
+		}
+	})
+
+	t.Run("Bool", func(t *testing.T) {
+		tests := []struct {
+			key       string
+			want      bool
+			wantFound bool
+			wantErr   bool
+		}{
+			{key: "debug", want: true, wantFound: true},
+			{key: "verbose", want: false, wantFound: true},
+			{key: "on", want: true, wantFound: true},
+			{key: "missing"},
+			{key: "empty", wantFound: true, wantErr: true},
+			{key: "page", wantFound: true, wantErr: true},
+		}
+		for _, tt := range tests {
+			got, found, err := GetQueryParamBool(query, tt.key)
+			if got != tt.want || found != tt.wantFound || (err != nil) != tt.wantErr {
+				t.Errorf("GetQueryParamBool(%q) = %v, %v, %v, want %v, %v, error %v",
+					tt.key, got, found, err, tt.want, tt.wantFound, tt.wantErr)
+			}
+		}
+	})
+
+	t.Run("Float", func(t *testing.T) {
+		tests := []struct {
+			key       string
+			want      float64
+			wantFound bool
+			wantErr   bool
+		}{
+			{key: "ratio", want: 0.25, wantFound: true},
+			{key: "exp", want: 1000, wantFound: true},
+			{key: "page", want: 2, wantFound: true},
+			{key: "missing"},
+			{key: "ttl", wantFound: true, wantErr: true},
+		}
+		for _, tt := range tests {
+			got, found, err := GetQueryParamFloat(query, tt.key)
+			if got != tt.want || found != tt.wantFound || (err != nil) != tt.wantErr {
+				t.Errorf("GetQueryParamFloat(%q) = %v, %v, %v, want %v, %v, error %v",
+					tt.key, got, found, err, tt.want, tt.wantFound, tt.wantErr)
+			}
+		}
+	})
+
+	t.Run("Duration", func(t *testing.T) {
+		got, found, err := GetQueryParamDuration(query, "ttl")
+		if got != 90*time.Minute || !found || err != nil {
+			t.Errorf("GetQueryParamDuration() = %v, %v, %v", got, found, err)
+		}
+		if _, found, err := GetQueryParamDuration(query, "page"); !found || err == nil {
+			t.Errorf("GetQueryParamDuration() = %v, %v, want an error", found, err)
+		}
+	})
+
+	t.Run("Time", func(t *testing.T) {
+		want := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+		got, found, err := GetQueryParamTime(query, "since", "")
+		if !got.Equal(want) || !found || err != nil {
+			t.Errorf("GetQueryParamTime() = %v, %v, %v", got, found, err)
+		}
+		got, found, err = GetQueryParamTime(query, "day", time.DateOnly)
+		if !got.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) || !found || err != nil {
+			t.Errorf("GetQueryParamTime() = %v, %v, %v", got, found, err)
+		}
+		var parseErr *time.ParseError
+		if _, found, err := GetQueryParamTime(query, "day", ""); !found || !errors.As(err, &parseErr) {
+			t.Errorf("GetQueryParamTime() = %v, %v, want a *time.ParseError", found, err)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, found, err := GetQueryParamInt(query, "ratio")
+		var convErr *ConversionError
+		if !found || !errors.As(err, &convErr) {
+			t.Fatalf("GetQueryParamInt() = %v, %v, want a *ConversionError", found, err)
+		}
+		if convErr.Key != "ratio" || convErr.Value != "0.25" || !errors.Is(err, strconv.ErrSyntax) {
+			t.Errorf("ConversionError = %+v", convErr)
+		}
+		want := `urlqm: cannot convert parameter "ratio" value "0.25": strconv.Atoi: parsing "0.25": invalid syntax`
+		if err.Error() != want {
+			t.Errorf("Error() = %q, want %q", err.Error(), want)
+		}
+
+		_, found, err = GetQueryParamInt(query, "big")
+		if !found || !errors.Is(err, strconv.ErrRange) {
+			t.Errorf("GetQueryParamInt() = %v, %v, want %v", found, err, strconv.ErrRange)
+		}
+
+		var parseErr *ParseError
+		if _, found, err := GetQueryParamInt(query, "bad"); !found || !errors.As(err, &parseErr) {
+			t.Errorf("GetQueryParamInt() = %v, %v, want a *ParseError", found, err)
+		}
+		if _, found, err := GetQueryParamBool("bad=%zz", "bad"); !found || !errors.As(err, &parseErr) {
+			t.Errorf("GetQueryParamBool() = %v, %v, want a *ParseError", found, err)
+		}
+	})
+
+	t.Run("Parser", func(t *testing.T) {
+		p := Parser{Separators: ";", DecodeKeys: true}
+		got, found, err := p.GetQueryParamInt("a=1;ключ=5", "ключ")
+		if got != 5 || !found || err != nil {
+			t.Errorf("GetQueryParamInt() = %v, %v, %v", got, found, err)
+		}
+		b, found, err := p.GetQueryParamBool("a=1;debug", "debug")
+		if !b || !found || err != nil {
+			t.Errorf("GetQueryParamBool() = %v, %v, %v", b, found, err)
+		}
+	})
+}
+
+func TestSetQueryParamTyped(t *testing.T) {
+	query := "page=1&q=go&page=2"
+	SetQueryParamInt(&query, "page", 3)
+	SetQueryParamBool(&query, "debug", true)
+	SetQueryParamFloat(&query, "ratio", 0.000001)
+	SetQueryParamDuration(&query, "ttl", 90*time.Minute)
+	SetQueryParamTime(&query, "since", time.Date(2024, 5, 1, 10, 0, 0, 0, time.FixedZone("", 2*3600)), "")
+	SetQueryParamTime(&query, "day", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), time.DateOnly)
+	want := "page=3&q=go&debug=true&ratio=0.000001&ttl=1h30m0s&since=2024-05-01T10%3A00%3A00%2B02%3A00&day=2024-05-01"
+	if query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+
+	since, _, err := GetQueryParamTime(query, "since", "")
+	if err != nil || !since.Equal(time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("GetQueryParamTime() = %v, %v", since, err)
+	}
+
+	query = "a=1"
+	Parser{Separators: ";", Escaper: RFC3986Escaper}.SetQueryParamTime(&query, "t",
+		time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), time.Kitchen)
+	if want := "a=1;t=10%3A00AM"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+}
+
+func TestParams_Typed(t *testing.T) {
+	params, _ := ParseQuery("page=2&debug&ratio=0.5&ttl=2s&since=2024-05-01&bad=x")
+
+	if got, found, err := params.GetInt("page"); got != 2 || !found || err != nil {
+		t.Errorf("GetInt() = %v, %v, %v", got, found, err)
+	}
+	if got, found, err := params.GetInt("missing"); got != 0 || found || err != nil {
+		t.Errorf("GetInt() = %v, %v, %v", got, found, err)
+	}
+	if got, found, err := params.GetBool("debug"); !got || !found || err != nil {
+		t.Errorf("GetBool() = %v, %v, %v", got, found, err)
+	}
+	if got, found, err := params.GetFloat("ratio"); got != 0.5 || !found || err != nil {
+		t.Errorf("GetFloat() = %v, %v, %v", got, found, err)
+	}
+	if got, found, err := params.GetDuration("ttl"); got != 2*time.Second || !found || err != nil {
+		t.Errorf("GetDuration() = %v, %v, %v", got, found, err)
+	}
+	got, found, err := params.GetTime("since", time.DateOnly)
+	if !got.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) || !found || err != nil {
+		t.Errorf("GetTime() = %v, %v, %v", got, found, err)
+	}
+
+	var convErr *ConversionError
+	if _, found, err := params.GetBool("bad"); !found || !errors.As(err, &convErr) || convErr.Key != "bad" || convErr.Value != "x" {
+		t.Errorf("GetBool() = %v, %v, want a *ConversionError", found, err)
+	}
+
+	empty := Params{{Key: "debug"}, {Key: "debug", Flag: true}}
+	if _, found, err := empty.GetBool("debug"); !found || !errors.As(err, &convErr) {
+		t.Errorf("GetBool() of an empty first value = %v, %v, want a *ConversionError", found, err)
+	}
+	if _, found, err := GetQueryParamBool("debug=&debug", "debug"); !found || !errors.As(err, &convErr) {
+		t.Errorf("GetQueryParamBool() of an empty first value = %v, %v, want a *ConversionError", found, err)
+	}
+
+	params.SetInt("page", 3)
+	params.SetBool("debug", false)
+	params.SetFloat("ratio", 1e21)
+	params.SetDuration("ttl", time.Millisecond)
+	params.SetTime("since", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), "")
+	params.SetTime("day", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), time.DateOnly)
+	want := "page=3&debug=false&ratio=1000000000000000000000&ttl=1ms&since=2024-06-01T00%3A00%3A00Z&bad=x&day=2024-06-01"
+	if got := params.Encode(); got != want {
+		t.Errorf("Encode() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,67 @@
+package urlqm
+
+import (
+	"net/url"
+	"sort"
+)
+
+// FromValues returns the params of the url.Values.
+// Since url.Values is a map, it has no order: the keys from keyOrder come first, in the given order,
+// and the rest of the keys follow sorted, like `url.Values.Encode` does. Values of a key keep their order.
+// Keys without values are skipped.
+func FromValues(values url.Values, keyOrder ...string) Params {
+	n := 0
+	for _, vs := range values {
+		n += len(vs)
+	}
+	params := make(Params, 0, n)
+
+	seen := make(map[string]bool, len(keyOrder))
+	for _, key := range keyOrder {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		for _, value := range values[key] {
+			params = append(params, Param{Key: key, Value: value})
+		}
+	}
+
+	rest := make([]string, 0, len(values))
+	for key := range values {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	for _, key := range rest {
+		for _, value := range values[key] {
+			params = append(params, Param{Key: key, Value: value})
+		}
+	}
+	return params
+}
+
+// URLValues returns the params as url.Values. The values of a key keep their order,
+// the order of the keys is lost. A valueless param (a flag) gets an empty value.
+// It is not named Values, because [Params.Values] is the iterator over the values of a key in go1.23 and later.
+func (p Params) URLValues() url.Values {
+	values := make(url.Values)
+	for _, param := range p {
+		values[param.Key] = append(values[param.Key], param.Value)
+	}
+	return values
+}
+
+// ParamsFromURL parses the query of the URL and returns its params, see [ParseParams].
+// The params remember their original form, like [Parser.KeepRaw] does,
+// so [Params.ApplyTo] changes only the bytes of the modified params.
+func ParamsFromURL(u *url.URL) (Params, error) {
+	return Parser{KeepRaw: true}.ParseQuery(u.RawQuery)
+}
+
+// ApplyTo sets the encoded params as the raw query of the URL. Unlike `url.Values.Encode`,
+// it keeps the order of the params.
+func (p Params) ApplyTo(u *url.URL) {
+	u.RawQuery = EncodeParams(p)
+}
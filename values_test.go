@@ -0,0 +1,71 @@
+package urlqm
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestFromValues(t *testing.T) {
+	values := url.Values{"b": {"2", "3"}, "a": {"1"}, "c": {"4"}, "empty": {}}
+	tests := []struct {
+		name     string
+		keyOrder []string
+		want     Params
+	}{
+		{
+			name: "Sorted",
+			want: Params{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}, {Key: "b", Value: "3"}, {Key: "c", Value: "4"}},
+		},
+		{
+			name:     "Key order first",
+			keyOrder: []string{"c", "b", "missing", "c"},
+			want:     Params{{Key: "c", Value: "4"}, {Key: "b", Value: "2"}, {Key: "b", Value: "3"}, {Key: "a", Value: "1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromValues(values, tt.keyOrder...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParams_URLValues(t *testing.T) {
+	p := Params{{Key: "b", Value: "2"}, {Key: "a", Value: "1"}, {Key: "b", Value: "3"}, {Key: "debug", Flag: true}}
+	want := url.Values{"a": {"1"}, "b": {"2", "3"}, "debug": {""}}
+	if got := p.URLValues(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Params.URLValues() = %v, want %v", got, want)
+	}
+	if got := (Params{}).URLValues(); got == nil || len(got) != 0 {
+		t.Errorf("Params.URLValues() = %v, want empty url.Values", got)
+	}
+}
+
+func TestParamsFromURL(t *testing.T) {
+	u, err := url.Parse("https://example.com/path?z=1;y=%7e+a&debug&x=%2F#frag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := ParamsFromURL(u)
+	if err != nil {
+		t.Fatalf("ParamsFromURL() error = %v", err)
+	}
+	if got, want := params.Get("y"), "~ a"; got != want {
+		t.Errorf("ParamsFromURL() y = %q, want %q", got, want)
+	}
+
+	// untouched params keep their bytes, and the order is never changed.
+	params.Set("x", "/b")
+	params.Add("a", "1")
+	params.ApplyTo(u)
+	if got, want := u.String(), "https://example.com/path?z=1;y=%7e+a&debug&x=%2Fb&a=1#frag"; got != want {
+		t.Errorf("Params.ApplyTo() = %q, want %q", got, want)
+	}
+
+	(Params{}).ApplyTo(u)
+	if got, want := u.String(), "https://example.com/path#frag"; got != want {
+		t.Errorf("Params.ApplyTo() = %q, want %q", got, want)
+	}
+}